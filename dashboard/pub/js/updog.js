@@ -126,17 +126,49 @@ function processAppMessage(e) {
 				servDiv.children('.inst_table').children('table').append(instDiv);
 			}
 
-			instDiv.children('.rtd').text(toMsFormatted(inst.response_time));
+			var indText = ia;
+			if (inst.streak > 0) {
+				indText += (inst.up ? ' (failing ' : ' (recovering ')+inst.streak+'/'+inst.streak_needed+')';
+			}
+			instDiv.children('.ind').filter(function() {
+				return $(this).text() !== indText
+			}).text(indText);
+			var instTitle = '';
+			if (inst.error) {
+				instTitle = (inst.error_class ? inst.error_class+': ' : '')+inst.error;
+			}
+			if (inst.resolved_ip) {
+				instTitle += (instTitle ? '\n' : '')+'ip: '+inst.resolved_ip;
+			}
+			if (inst.status_code) {
+				instTitle += (instTitle ? '\n' : '')+'status code: '+inst.status_code;
+			}
+			if (inst.http_timing) {
+				var t = inst.http_timing;
+				instTitle += (instTitle ? '\n' : '')+'dns '+toMsFormatted(t.dns)+', connect '+toMsFormatted(t.connect)+
+					', tls '+toMsFormatted(t.tls)+', first byte '+toMsFormatted(t.first_byte)+', total '+toMsFormatted(t.total);
+			}
+			$.each(inst.addresses || {}, function(ip, addr) {
+				instTitle += (instTitle ? '\n' : '')+ip+': '+(addr.up ? (addr.degraded ? 'degraded' : 'up') : 'failed')+
+					(addr.error ? ' ('+addr.error+')' : '');
+			});
+			instDiv.attr('title', instTitle);
+			instDiv.children('.rtd').text(toMsFormatted(inst.response_time)).toggleClass('slow', !!inst.slow);
 			instDiv.children('.lcd').children('time').attr('title', inst.last_change);
 
-			if (inst.up && !instDiv.hasClass("up")) {
+			if (inst.up && !inst.degraded && !instDiv.hasClass("up")) {
 				instDiv.addClass("up");
-				instDiv.removeClass('failed');
+				instDiv.removeClass('degraded').removeClass('failed');
+			}
+
+			if (inst.up && inst.degraded && !instDiv.hasClass("degraded")) {
+				instDiv.addClass("degraded");
+				instDiv.removeClass('up').removeClass('failed');
 			}
 
 			if (!inst.up && !instDiv.hasClass("failed")) {
 				instDiv.addClass("failed");
-				instDiv.removeClass('up');
+				instDiv.removeClass('up').removeClass('degraded');
 			}
 		});
 
@@ -21,6 +21,7 @@ func (c *Client) Subscribe(conf *updog.Config) error {
 			c.Submit("updog.instances_total", ass.InstancesTotal, asts)
 			c.Submit("updog.instances_up", ass.InstancesUp, asts)
 			c.Submit("updog.instances_failed", ass.InstancesFailed, asts)
+			c.Submit("updog.instances_slow", ass.InstancesSlow, asts)
 			for an, a := range ass.Applications {
 				ac := c.NewClient(map[string]string{"application": an})
 				ats := a.TimeStamp
@@ -33,6 +34,7 @@ func (c *Client) Subscribe(conf *updog.Config) error {
 				ac.Submit("updog.application.instances_total", a.InstancesTotal, ats)
 				ac.Submit("updog.application.instances_up", a.InstancesUp, ats)
 				ac.Submit("updog.application.instances_failed", a.InstancesFailed, ats)
+				ac.Submit("updog.application.instances_slow", a.InstancesSlow, ats)
 				for sn, s := range a.Services {
 					sc := ac.NewClient(map[string]string{"service": sn})
 					sts := s.TimeStamp
@@ -41,11 +43,26 @@ func (c *Client) Subscribe(conf *updog.Config) error {
 					sc.Submit("updog.service.instances_total", s.InstancesTotal, sts)
 					sc.Submit("updog.service.instances_up", s.InstancesUp, sts)
 					sc.Submit("updog.service.instances_failed", s.InstancesFailed, sts)
+					sc.Submit("updog.service.instances_slow", s.InstancesSlow, sts)
 					for in, i := range s.Instances {
 						ic := sc.NewClient(map[string]string{"instance": in})
 						its := i.TimeStamp
 						ic.Submit("updog.instance.up", i.Up, its)
 						ic.Submit("updog.instance.response_time", i.ResponseTime, its)
+						ic.Submit("updog.instance.slow", i.Slow, its)
+						if t := i.HTTPTiming; t != nil {
+							ic.NewClient(map[string]string{"phase": "dns"}).Submit("updog.instance.http_timing", t.DNS, its)
+							ic.NewClient(map[string]string{"phase": "connect"}).Submit("updog.instance.http_timing", t.Connect, its)
+							ic.NewClient(map[string]string{"phase": "tls"}).Submit("updog.instance.http_timing", t.TLS, its)
+							ic.NewClient(map[string]string{"phase": "first_byte"}).Submit("updog.instance.http_timing", t.FirstByte, its)
+							ic.NewClient(map[string]string{"phase": "total"}).Submit("updog.instance.http_timing", t.Total, its)
+						}
+						for vn, v := range i.Values {
+							ic.NewClient(map[string]string{"value": vn}).Submit("updog.instance.value", v, its)
+						}
+						if i.Role != "" {
+							ic.NewClient(map[string]string{"role": i.Role}).Submit("updog.instance.role", 1, its)
+						}
 					}
 				}
 			}
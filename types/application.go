@@ -26,6 +26,7 @@ type ApplicationStatus struct {
 	InstancesTotal   int                      `json:"instances_total"`
 	InstancesUp      int                      `json:"instances_up"`
 	InstancesFailed  int                      `json:"instances_failed"`
+	InstancesSlow    int                      `json:"instances_slow"`
 	TimeStamp        time.Time                `json:"timestamp"`
 	LastChange       time.Time                `json:"last_change"`
 	idx, cidx        uint64
@@ -93,6 +94,7 @@ func (as *ApplicationStatus) recalculate() {
 	as.InstancesTotal = 0
 	as.InstancesUp = 0
 	as.InstancesFailed = 0
+	as.InstancesSlow = 0
 	for _, s := range as.Services {
 		as.ServicesTotal++
 		if !s.Failed && !s.Degraded {
@@ -109,6 +111,7 @@ func (as *ApplicationStatus) recalculate() {
 		as.InstancesTotal += s.InstancesTotal
 		as.InstancesUp += s.InstancesUp
 		as.InstancesFailed += s.InstancesFailed
+		as.InstancesSlow += s.InstancesSlow
 	}
 }
 
@@ -154,7 +157,8 @@ func (as *ApplicationStatus) copySummaryFrom(ias *ApplicationStatus) bool {
 		as.ServicesFailed == ias.ServicesFailed &&
 		as.InstancesTotal == ias.InstancesTotal &&
 		as.InstancesUp == ias.InstancesUp &&
-		as.InstancesFailed == ias.InstancesFailed
+		as.InstancesFailed == ias.InstancesFailed &&
+		as.InstancesSlow == ias.InstancesSlow
 
 	if c {
 		return false
@@ -169,6 +173,7 @@ func (as *ApplicationStatus) copySummaryFrom(ias *ApplicationStatus) bool {
 	as.InstancesTotal = ias.InstancesTotal
 	as.InstancesUp = ias.InstancesUp
 	as.InstancesFailed = ias.InstancesFailed
+	as.InstancesSlow = ias.InstancesSlow
 	return true
 }
 
@@ -41,6 +41,7 @@ type ApplicationsStatus struct {
 	InstancesTotal       int                          `json:"instances_total"`
 	InstancesUp          int                          `json:"instances_up"`
 	InstancesFailed      int                          `json:"instances_failed"`
+	InstancesSlow        int                          `json:"instances_slow"`
 	TimeStamp            time.Time                    `json:"timestamp"`
 	LastChange           time.Time                    `json:"last_change"`
 	idx, cidx            uint64
@@ -121,6 +122,7 @@ func (as *ApplicationsStatus) recalculate() {
 	as.InstancesTotal = 0
 	as.InstancesUp = 0
 	as.InstancesFailed = 0
+	as.InstancesSlow = 0
 	for _, a := range as.Applications {
 		as.ApplicationsTotal++
 		if !a.Failed && !a.Degraded {
@@ -141,6 +143,7 @@ func (as *ApplicationsStatus) recalculate() {
 		as.InstancesTotal += a.InstancesTotal
 		as.InstancesUp += a.InstancesUp
 		as.InstancesFailed += a.InstancesFailed
+		as.InstancesSlow += a.InstancesSlow
 	}
 }
 
@@ -190,7 +193,8 @@ func (as *ApplicationsStatus) copySummaryFrom(ias *ApplicationsStatus) bool {
 		as.ServicesFailed == ias.ServicesFailed &&
 		as.InstancesTotal == ias.InstancesTotal &&
 		as.InstancesUp == ias.InstancesUp &&
-		as.InstancesFailed == ias.InstancesFailed
+		as.InstancesFailed == ias.InstancesFailed &&
+		as.InstancesSlow == ias.InstancesSlow
 
 	if c {
 		return false
@@ -209,6 +213,7 @@ func (as *ApplicationsStatus) copySummaryFrom(ias *ApplicationsStatus) bool {
 	as.InstancesTotal = ias.InstancesTotal
 	as.InstancesUp = ias.InstancesUp
 	as.InstancesFailed = ias.InstancesFailed
+	as.InstancesSlow = ias.InstancesSlow
 	return true
 }
 
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+//DNS represents a check type of dns resolution against a resolver
+const DNS = "dns"
+
+func init() {
+	RegisterChecker(DNS, newDNSChecker)
+}
+
+type dnsChecker struct {
+	Name       string   `json:"name"`
+	RecordType string   `json:"record_type"`
+	Protocol   string   `json:"protocol,omitempty"`
+	Expect     []string `json:"expect,omitempty"`
+	MinAnswers int      `json:"min_answers,omitempty"`
+	qtype      uint16
+}
+
+func newDNSChecker(options json.RawMessage) (Checker, error) {
+	c := &dnsChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Name == "" {
+		return nil, errors.New("dns check requires a name")
+	}
+	if c.RecordType == "" {
+		c.RecordType = "A"
+	}
+	c.RecordType = strings.ToUpper(c.RecordType)
+	switch c.RecordType {
+	case "A", "AAAA", "SRV", "CNAME", "TXT":
+		c.qtype = dns.StringToType[c.RecordType]
+	default:
+		return nil, fmt.Errorf("unsupported dns record_type %q", c.RecordType)
+	}
+	switch c.Protocol {
+	case "":
+		c.Protocol = "udp"
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("unsupported dns protocol %q", c.Protocol)
+	}
+	return c, nil
+}
+
+func (c *dnsChecker) Check(ctx context.Context, address string) CheckResult {
+	host, port := splitAddress(address, "53")
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(c.Name), c.qtype)
+	client := &dns.Client{Net: c.Protocol}
+	in, _, err := client.ExchangeContext(ctx, m, net.JoinHostPort(host, port))
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if in.Rcode != dns.RcodeSuccess {
+		return CheckResult{Err: fmt.Errorf("dns response %v", dns.RcodeToString[in.Rcode])}
+	}
+
+	answers := make(map[string]bool)
+	for _, rr := range in.Answer {
+		if rr.Header().Rrtype != c.qtype {
+			continue
+		}
+		answers[dnsAnswer(rr)] = true
+	}
+
+	r := CheckResult{Values: map[string]float64{"answers": float64(len(answers))}}
+	if len(answers) < c.MinAnswers {
+		r.Err = fmt.Errorf("got %v answers, expected at least %v", len(answers), c.MinAnswers)
+		return r
+	}
+	for _, e := range c.Expect {
+		if !answers[normalizeDNSAnswer(c.qtype, e)] {
+			r.Err = fmt.Errorf("answer %q not found", e)
+			return r
+		}
+	}
+	r.Up = true
+	return r
+}
+
+//dnsAnswer formats the data of a record the way it would be written in the expect option
+func dnsAnswer(rr dns.RR) string {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String()
+	case *dns.AAAA:
+		return v.AAAA.String()
+	case *dns.CNAME:
+		return strings.ToLower(dns.Fqdn(v.Target))
+	case *dns.TXT:
+		return strings.Join(v.Txt, "")
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, strings.ToLower(dns.Fqdn(v.Target)))
+	}
+	return rr.String()
+}
+
+func normalizeDNSAnswer(qtype uint16, s string) string {
+	switch qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		if ip := net.ParseIP(s); ip != nil {
+			return ip.String()
+		}
+	case dns.TypeCNAME:
+		return strings.ToLower(dns.Fqdn(s))
+	case dns.TypeSRV:
+		f := strings.Fields(s)
+		if len(f) == 4 {
+			f[3] = strings.ToLower(dns.Fqdn(f[3]))
+		}
+		return strings.Join(f, " ")
+	}
+	return s
+}
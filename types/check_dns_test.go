@@ -0,0 +1,130 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+//startDNSServer serves the records on a loopback udp port, answering names
+//in refused with REFUSED, and unknown names with NXDOMAIN. It returns the
+//address of the server, and a func to stop it.
+func startDNSServer(t *testing.T, records []string, refused ...string) (string, func()) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rrs []dns.RR
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		q := req.Question[0]
+		for _, n := range refused {
+			if q.Name == n {
+				m.Rcode = dns.RcodeRefused
+			}
+		}
+		for _, rr := range rrs {
+			if rr.Header().Name == q.Name {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		if len(m.Answer) == 0 && m.Rcode == dns.RcodeSuccess {
+			m.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(m)
+	})
+	started := make(chan struct{})
+	srv := &dns.Server{PacketConn: pc, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go func() { _ = srv.ActivateAndServe() }()
+	<-started
+	return pc.LocalAddr().String(), func() { _ = srv.Shutdown() }
+}
+
+func TestDNSCheck(t *testing.T) {
+	address, stop := startDNSServer(t, []string{
+		"www.example.com. 60 IN A 192.0.2.1",
+		"www.example.com. 60 IN A 192.0.2.2",
+		"alias.example.com. 60 IN CNAME WWW.Example.COM.",
+		"_http._tcp.example.com. 60 IN SRV 10 20 80 WWW.example.com.",
+		"v6.example.com. 60 IN AAAA 2001:db8::1",
+	}, "refused.example.com.")
+	defer stop()
+
+	tests := []struct {
+		name    string
+		options string
+		up      bool
+		answers float64
+	}{
+		{"a", `{"name": "www.example.com"}`, true, 2},
+		{"a expected", `{"name": "www.example.com", "expect": ["192.0.2.2", "192.0.2.1"]}`, true, 2},
+		{"a missing", `{"name": "www.example.com", "expect": ["192.0.2.3"]}`, false, 2},
+		{"min answers", `{"name": "www.example.com", "min_answers": 2}`, true, 2},
+		{"too few answers", `{"name": "www.example.com", "min_answers": 3}`, false, 2},
+		{"aaaa normalized", `{"name": "v6.example.com", "record_type": "aaaa", "expect": ["2001:DB8:0::1"]}`, true, 1},
+		{"cname normalized", `{"name": "alias.example.com", "record_type": "CNAME", "expect": ["www.example.com"]}`, true, 1},
+		{"srv normalized", `{"name": "_http._tcp.example.com", "record_type": "SRV", "expect": ["10 20 80 www.EXAMPLE.com"]}`, true, 1},
+		{"srv wrong port", `{"name": "_http._tcp.example.com", "record_type": "SRV", "expect": ["10 20 8080 www.example.com"]}`, false, 1},
+		{"other type ignored", `{"name": "www.example.com", "record_type": "AAAA", "min_answers": 1}`, false, 0},
+		{"nxdomain", `{"name": "missing.example.com"}`, false, 0},
+		{"refused", `{"name": "refused.example.com"}`, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newDNSChecker(json.RawMessage(tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			r := c.Check(ctx, address)
+			if r.Up != tt.up {
+				t.Errorf("up is %v, expected %v: %v", r.Up, tt.up, r.Err)
+			}
+			if r.Up != (r.Err == nil) {
+				t.Errorf("up is %v with error %v", r.Up, r.Err)
+			}
+			if r.Values["answers"] != tt.answers {
+				t.Errorf("answers is %v, expected %v", r.Values["answers"], tt.answers)
+			}
+		})
+	}
+}
+
+func TestDNSCheckRcode(t *testing.T) {
+	address, stop := startDNSServer(t, nil, "refused.example.com.")
+	defer stop()
+	c, err := newDNSChecker(json.RawMessage(`{"name": "refused.example.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.Check(context.Background(), address)
+	if r.Err == nil || r.Err.Error() != "dns response REFUSED" {
+		t.Errorf("expected REFUSED error, got %v", r.Err)
+	}
+}
+
+func TestNewDNSCheckerInvalid(t *testing.T) {
+	for _, options := range []string{
+		`{}`,
+		`{"name": "example.com", "record_type": "MX"}`,
+		`{"name": "example.com", "protocol": "sctp"}`,
+	} {
+		_, err := newDNSChecker(json.RawMessage(options))
+		if err == nil {
+			t.Errorf("expected error for %v", options)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+//Exec represents a check type of running a nagios compatible plugin
+const Exec = "exec"
+
+//nagios plugin exit codes
+const (
+	execOK       = 0
+	execWarning  = 1
+	execCritical = 2
+)
+
+func init() {
+	RegisterChecker(Exec, newExecChecker)
+}
+
+//execChecker runs a command, replacing {address}, {host} and {port} in the arguments
+type execChecker struct {
+	Command []string `json:"command"`
+}
+
+func newExecChecker(options json.RawMessage) (Checker, error) {
+	c := &execChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return nil, errors.New("exec check requires a command")
+	}
+	return c, nil
+}
+
+func (c *execChecker) Check(ctx context.Context, address string) CheckResult {
+	host, port := splitAddress(address, "")
+	rep := strings.NewReplacer("{address}", address, "{host}", host, "{port}", port)
+	args := make([]string, len(c.Command))
+	for i, a := range c.Command {
+		args[i] = rep.Replace(a)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+	err := cmd.Start()
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+
+	// kill the whole process group, so plugins which fork can't hold the check open
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	err = cmd.Wait()
+	close(done)
+	if ctx.Err() != nil {
+		return CheckResult{Err: &classError{ErrorClassTimeout, fmt.Errorf("command killed: %v", ctx.Err())}}
+	}
+
+	code := execOK
+	if err != nil {
+		ee, ok := err.(*exec.ExitError)
+		if !ok {
+			return CheckResult{Err: err}
+		}
+		code = ee.Sys().(syscall.WaitStatus).ExitStatus()
+	}
+
+	out := stdout.String()
+	if strings.TrimSpace(out) == "" {
+		out = stderr.String()
+	}
+	text, perf := parsePluginOutput(out)
+	r := CheckResult{
+		Status: text,
+		Values: parsePerfData(perf),
+	}
+	switch code {
+	case execOK:
+		r.Up = true
+	case execWarning:
+		// the status is already the output of the plugin
+		r.Up = true
+		r.Degraded = true
+	case execCritical:
+		r.Err = errors.New(text)
+	default:
+		r.Err = fmt.Errorf("unknown exit code %v: %v", code, text)
+	}
+	return r
+}
+
+//parsePluginOutput returns the first line of the plugin output, and all of the perfdata
+func parsePluginOutput(out string) (text, perf string) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var perfs []string
+	for i, line := range lines {
+		p := strings.SplitN(line, "|", 2)
+		if i == 0 {
+			text = strings.TrimSpace(p[0])
+		}
+		if len(p) > 1 {
+			perfs = append(perfs, strings.TrimSpace(p[1]))
+		}
+	}
+	return text, strings.Join(perfs, " ")
+}
+
+//parsePerfData parses 'label'=value[UOM];[warn];[crit];[min];[max] pairs into a map of label to value
+func parsePerfData(perf string) map[string]float64 {
+	values := make(map[string]float64)
+	for perf = strings.TrimSpace(perf); perf != ""; perf = strings.TrimSpace(perf) {
+		var label string
+		if perf[0] == '\'' {
+			end := strings.Index(perf[1:], "'=")
+			if end < 0 {
+				break
+			}
+			label, perf = perf[1:end+1], perf[end+3:]
+		} else {
+			eq := strings.Index(perf, "=")
+			if eq < 0 {
+				break
+			}
+			label, perf = perf[:eq], perf[eq+1:]
+		}
+
+		var field string
+		if sp := strings.IndexAny(perf, " \t"); sp >= 0 {
+			field, perf = perf[:sp], perf[sp:]
+		} else {
+			field, perf = perf, ""
+		}
+		field = strings.SplitN(field, ";", 2)[0]
+		field = strings.TrimRightFunc(field, func(r rune) bool {
+			return !strings.ContainsRune("0123456789.", r)
+		})
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			continue
+		}
+		values[label] = v
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
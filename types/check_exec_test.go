@@ -0,0 +1,63 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePluginOutput(t *testing.T) {
+	tests := []struct {
+		name, out, text, perf string
+	}{
+		{"text only", "OK - all good\n", "OK - all good", ""},
+		{"perfdata", "OK - 5 users | users=5;10;20", "OK - 5 users", "users=5;10;20"},
+		{"long text", "WARNING - load\nload was high\nfor a while\n", "WARNING - load", ""},
+		{
+			"multi-line perfdata",
+			"OK - disk | /=2643MB;5948;5958;0;5968\n/boot 68MB free\n/home 69357MB free | /boot=68MB;88;93;0;98\n/home=69357MB;253404;253409;0;253414",
+			"OK - disk",
+			"/=2643MB;5948;5958;0;5968 /boot=68MB;88;93;0;98",
+		},
+		{"empty", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, perf := parsePluginOutput(tt.out)
+			if text != tt.text {
+				t.Errorf("text is %q, expected %q", text, tt.text)
+			}
+			if perf != tt.perf {
+				t.Errorf("perf is %q, expected %q", perf, tt.perf)
+			}
+		})
+	}
+}
+
+func TestParsePerfData(t *testing.T) {
+	tests := []struct {
+		name   string
+		perf   string
+		values map[string]float64
+	}{
+		{"empty", "", nil},
+		{"plain", "users=5", map[string]float64{"users": 5}},
+		{"thresholds", "load1=0.5;5;10;0", map[string]float64{"load1": 0.5}},
+		{"units", "time=0.25s size=1024B used=87.5% count=3c", map[string]float64{"time": 0.25, "size": 1024, "used": 87.5, "count": 3}},
+		{"quoted label", "'a b'=5ms;1;2 c=3", map[string]float64{"a b": 5, "c": 3}},
+		{"quoted label last", "c=3 'disk /var'=90%;80;95", map[string]float64{"c": 3, "disk /var": 90}},
+		{"quoted label with equals", "'x=y'=1", map[string]float64{"x=y": 1}},
+		{"extra whitespace", "  a=1 \t b=2  ", map[string]float64{"a": 1, "b": 2}},
+		{"unparsable value skipped", "a=U b=2", map[string]float64{"b": 2}},
+		{"negative", "temp=-4.5C", map[string]float64{"temp": -4.5}},
+		{"unterminated quote", "'a b=5", nil},
+		{"missing equals", "garbage", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := parsePerfData(tt.perf)
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values are %v, expected %v", values, tt.values)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+//GRPCHealth represents a check type of the grpc health checking protocol
+const GRPCHealth = "grpc_health"
+
+func init() {
+	RegisterChecker(GRPCHealth, newGRPCHealthChecker)
+}
+
+type grpcHealthChecker struct {
+	Service  string    `json:"service,omitempty"`
+	TLS      bool      `json:"tls,omitempty"`
+	HTTPOpts *HTTPOpts `json:"http_options,omitempty"`
+	dialOpts []grpc.DialOption
+}
+
+func newGRPCHealthChecker(options json.RawMessage) (Checker, error) {
+	c := &grpcHealthChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.TLS {
+		if c.HTTPOpts == nil {
+			c.HTTPOpts = &HTTPOpts{}
+		}
+		c.dialOpts = append(c.dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(newTLSConfig(c.HTTPOpts))))
+	} else {
+		c.dialOpts = append(c.dialOpts, grpc.WithInsecure())
+	}
+	return c, nil
+}
+
+func (c *grpcHealthChecker) Check(ctx context.Context, address string) CheckResult {
+	dialOpts := c.dialOpts
+	if ra, ok := resolvedIP(ctx); ok {
+		// keep the host name the address was resolved from for tls verification
+		dialOpts = append(dialOpts[:len(dialOpts):len(dialOpts)], grpc.WithAuthority(ra.host))
+	}
+	conn, err := grpc.DialContext(ctx, address, dialOpts...)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.WithError(err).Error("Error closing grpc connection")
+		}
+	}()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	r := CheckResult{
+		Up:     resp.Status == healthpb.HealthCheckResponse_SERVING,
+		Status: resp.Status.String(),
+	}
+	if !r.Up {
+		r.Err = errors.New("grpc health status " + resp.Status.String())
+	}
+	return r
+}
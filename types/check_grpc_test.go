@@ -0,0 +1,65 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCHealthCheck(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := health.NewServer()
+	hs.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("not_serving", healthpb.HealthCheckResponse_NOT_SERVING)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	tests := []struct {
+		service string
+		up      bool
+		status  string
+		code    codes.Code
+	}{
+		{"", true, "SERVING", codes.OK},
+		{"serving", true, "SERVING", codes.OK},
+		{"not_serving", false, "NOT_SERVING", codes.OK},
+		{"unknown", false, "", codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			options, _ := json.Marshal(map[string]string{"service": tt.service})
+			c, err := newGRPCHealthChecker(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			r := c.Check(ctx, l.Addr().String())
+			if r.Up != tt.up {
+				t.Errorf("up is %v, expected %v: %v", r.Up, tt.up, r.Err)
+			}
+			if r.Up != (r.Err == nil) {
+				t.Errorf("up is %v with error %v", r.Up, r.Err)
+			}
+			if r.Status != tt.status {
+				t.Errorf("status is %q, expected %q", r.Status, tt.status)
+			}
+			if tt.code != codes.OK && status.Code(r.Err) != tt.code {
+				t.Errorf("error code is %v, expected %v: %v", status.Code(r.Err), tt.code, r.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/TrilliumIT/updog/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+//HTTPStatus represents a check type of http status
+const HTTPStatus = "http_status"
+
+func init() {
+	RegisterChecker(HTTPStatus, newHTTPStatusChecker)
+}
+
+const defaultMaxBodySize = 1 << 20
+
+//HTTPOpts are the http client options for checking the instances of this service.
+//Connections are kept open between checks unless connection_reuse is false.
+type HTTPOpts struct {
+	HTTPMethod         string            `json:"http_method"`
+	SkipTLSVerify      bool              `json:"skip_tls_verify"`
+	CA                 string            `json:"ca"`
+	ClientCert         string            `json:"client_cert"`
+	ClientKey          string            `json:"client_key"`
+	ExpectBodyContains string            `json:"expect_body_contains,omitempty"`
+	ExpectBodyRegex    string            `json:"expect_body_regex,omitempty"`
+	MaxBodySize        int64             `json:"max_body_size,omitempty"`
+	ExpectHeaders      map[string]string `json:"expect_headers,omitempty"`
+	ExpectStatus       StatusCodes       `json:"expected_status,omitempty"`
+	FollowRedirects    bool              `json:"follow_redirects,omitempty"`
+	Headers            httpHeaders       `json:"headers,omitempty"`
+	Host               string            `json:"host,omitempty"`
+	BasicAuth          *BasicAuth        `json:"basic_auth,omitempty"`
+	BearerTokenFile    string            `json:"bearer_token_file,omitempty"`
+	Body               Secret            `json:"body,omitempty"`
+	ConnectionReuse    *bool             `json:"connection_reuse,omitempty"`
+	bodyRegex          *regexp.Regexp
+	bearerToken        *fileSecret
+}
+
+//BasicAuth are the credentials sent with each http request.
+//The password may be given inline, or read from password_file.
+type BasicAuth struct {
+	Username     string `json:"username"`
+	Password     Secret `json:"password,omitempty"`
+	PasswordFile string `json:"password_file,omitempty"`
+	passwordFile *fileSecret
+}
+
+func (a *BasicAuth) password() (string, error) {
+	if a.passwordFile == nil {
+		return string(a.Password), nil
+	}
+	return a.passwordFile.get()
+}
+
+func (opts *HTTPOpts) compile() (err error) {
+	if opts.ExpectBodyRegex != "" {
+		opts.bodyRegex, err = regexp.Compile(opts.ExpectBodyRegex)
+		if err != nil {
+			return fmt.Errorf("invalid expect_body_regex: %v", err)
+		}
+	}
+	if opts.MaxBodySize < 0 {
+		return fmt.Errorf("invalid max_body_size: %v", opts.MaxBodySize)
+	}
+	if opts.BasicAuth != nil {
+		if opts.BasicAuth.Password != "" && opts.BasicAuth.PasswordFile != "" {
+			return errors.New("basic_auth password and password_file are exclusive")
+		}
+		if opts.BasicAuth.PasswordFile != "" {
+			opts.BasicAuth.passwordFile = newFileSecret(opts.BasicAuth.PasswordFile)
+		}
+	}
+	if opts.BearerTokenFile != "" {
+		if opts.BasicAuth != nil {
+			return errors.New("basic_auth and bearer_token_file are exclusive")
+		}
+		opts.bearerToken = newFileSecret(opts.BearerTokenFile)
+	}
+	return nil
+}
+
+func (opts *HTTPOpts) assertsBody() bool {
+	return opts.ExpectBodyContains != "" || opts.bodyRegex != nil || opts.MaxBodySize > 0
+}
+
+//newRequest creates the request to send to the url
+func (opts *HTTPOpts) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	var body io.Reader
+	if opts.Body != "" {
+		body = strings.NewReader(string(opts.Body))
+	}
+	req, err := http.NewRequest(opts.HTTPMethod, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+	if opts.Host != "" {
+		req.Host = opts.Host
+	}
+	if _, ok := resolvedIP(ctx); ok {
+		// connections to each resolved address must not be reused for the others
+		req.Close = true
+	}
+	if opts.BasicAuth != nil {
+		pw, err := opts.BasicAuth.password()
+		if err != nil {
+			return nil, fmt.Errorf("error reading basic_auth password: %v", err)
+		}
+		req.SetBasicAuth(opts.BasicAuth.Username, pw)
+	}
+	if opts.bearerToken != nil {
+		token, err := opts.bearerToken.get()
+		if err != nil {
+			return nil, fmt.Errorf("error reading bearer_token_file: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req.WithContext(ctx), nil
+}
+
+//checkStatus returns an error if the status code is not one of the expected codes
+func (opts *HTTPOpts) checkStatus(resp *http.Response) error {
+	expected := opts.ExpectStatus
+	if len(expected) == 0 {
+		expected = defaultStatusCodes
+	}
+	if !expected.Contains(resp.StatusCode) {
+		return &classError{ErrorClassHTTPStatus, fmt.Errorf("unexpected status code %v", resp.StatusCode)}
+	}
+	return nil
+}
+
+//checkResponse returns an error describing the first response assertion which failed
+func (opts *HTTPOpts) checkResponse(resp *http.Response) error {
+	err := opts.checkHeaders(resp)
+	if err != nil || !opts.assertsBody() {
+		return err
+	}
+	body, err := opts.readBody(resp)
+	if err != nil {
+		return err
+	}
+	return opts.checkBody(body)
+}
+
+func (opts *HTTPOpts) checkHeaders(resp *http.Response) error {
+	for k, v := range opts.ExpectHeaders {
+		vs, ok := resp.Header[http.CanonicalHeaderKey(k)]
+		if !ok {
+			return fmt.Errorf("header %v missing", k)
+		}
+		if v == "" {
+			continue
+		}
+		found := false
+		for _, hv := range vs {
+			if hv == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("header %v is %q, expected %q", k, strings.Join(vs, ", "), v)
+		}
+	}
+	return nil
+}
+
+//readBody reads the response body, failing if it is larger than max_body_size
+func (opts *HTTPOpts) readBody(resp *http.Response) ([]byte, error) {
+	max := opts.MaxBodySize
+	if max == 0 {
+		max = defaultMaxBodySize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %v", err)
+	}
+	if int64(len(body)) > max {
+		return nil, fmt.Errorf("body larger than max_body_size %v", max)
+	}
+	return body, nil
+}
+
+func (opts *HTTPOpts) checkBody(body []byte) error {
+	if opts.ExpectBodyContains != "" && !strings.Contains(string(body), opts.ExpectBodyContains) {
+		return fmt.Errorf("body does not contain %q", opts.ExpectBodyContains)
+	}
+	if opts.bodyRegex != nil && !opts.bodyRegex.Match(body) {
+		return fmt.Errorf("body does not match %q", opts.ExpectBodyRegex)
+	}
+	return nil
+}
+
+type httpStatusChecker struct {
+	HTTPMethod string    `json:"http_method,omitempty"`
+	HTTPOpts   *HTTPOpts `json:"http_options"`
+	client     *http.Client
+}
+
+func newHTTPStatusChecker(options json.RawMessage) (Checker, error) {
+	c := &httpStatusChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if c.HTTPOpts.HTTPMethod == "" {
+		c.HTTPOpts.HTTPMethod = c.HTTPMethod
+	}
+	if c.HTTPOpts.HTTPMethod == "" {
+		c.HTTPOpts.HTTPMethod = "GET"
+	}
+	err = c.HTTPOpts.compile()
+	if err != nil {
+		return nil, err
+	}
+	c.client = newHTTPClient(c.HTTPOpts)
+	return c, nil
+}
+
+//newTLSConfig builds the tls config from the ca, client certificate and verify options
+func newTLSConfig(opts *HTTPOpts) *tls.Config {
+	l := log.WithFields(log.Fields{"method": opts.HTTPMethod, "skip_tls_verify": opts.SkipTLSVerify})
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.SkipTLSVerify,
+	}
+
+	if opts.Host != "" {
+		// the overridden host is also the name the server's certificate is for
+		tlsConfig.ServerName, _ = splitAddress(opts.Host, "")
+	}
+
+	if opts.CA != "" {
+		rootCert := x509.NewCertPool()
+		data, err := ioutil.ReadFile(opts.CA)
+		if err != nil {
+			l.WithError(err).WithField("file", opts.CA).Error("Error reading CA file")
+		}
+		ok := rootCert.AppendCertsFromPEM(data)
+		if !ok {
+			l.Error("Error adding ca to root store")
+		}
+		tlsConfig.RootCAs = rootCert
+	}
+
+	if (opts.ClientCert != "") || (opts.ClientKey != "") {
+		clientCert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			l.WithField("certificate", opts.ClientCert).WithField("key", opts.ClientKey).WithError(err).Error("Unable to load client tls key")
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, clientCert)
+	}
+
+	return tlsConfig
+}
+
+func newHTTPClient(opts *HTTPOpts) *http.Client {
+	tlsConfig := newTLSConfig(opts)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{
+			DialContext:       dialContext,
+			TLSClientConfig:   tlsConfig,
+			DisableKeepAlives: opts.ConnectionReuse != nil && !*opts.ConnectionReuse,
+		},
+	}
+
+	if opts.FollowRedirects {
+		// nil uses the default policy of following up to 10 redirects
+		client.CheckRedirect = nil
+	}
+
+	return client
+}
+
+func (c *httpStatusChecker) Check(ctx context.Context, address string) CheckResult {
+	opts := c.HTTPOpts
+	l := log.WithFields(log.Fields{"method": opts.HTTPMethod, "address": address, "skip_tls_verify": opts.SkipTLSVerify})
+	timer := newHTTPTimer()
+	req, err := opts.newRequest(timer.withContext(ctx), address)
+	if err != nil {
+		l.WithError(err).Error("Failed to create http request.")
+		return CheckResult{Err: err}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		l.WithError(err).Error("Error doing http request")
+		return CheckResult{Err: err, HTTPTiming: timer.timing()}
+	}
+	defer utils.DiscardCloseBody(resp.Body)
+	redirects := redirectChain(resp)
+	err = opts.checkStatus(resp)
+	if err == nil {
+		err = opts.checkResponse(resp)
+	}
+	if err != nil {
+		l.WithError(err).Debug("Response assertion failed")
+	}
+	return CheckResult{
+		Up:               err == nil,
+		Err:              err,
+		Redirects:        redirects,
+		StatusCode:       resp.StatusCode,
+		HTTPTiming:       timer.timing(),
+		ConnectionReused: timer.reused(),
+	}
+}
+
+//redirectChain returns the urls which were redirected to, in order, to get the final response
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append([]string{req.URL.String()}, chain...)
+	}
+	return chain
+}
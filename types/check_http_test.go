@@ -0,0 +1,35 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPStatusCheckConnectionReused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := newHTTPStatusChecker(json.RawMessage(`{"http_options": {"follow_redirects": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, reused := range []bool{false, true} {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		r := c.Check(ctx, srv.URL+"/redirect")
+		cancel()
+		if !r.Up || len(r.Redirects) != 1 {
+			t.Fatalf("check %v is up %v with redirects %v: %v", n+1, r.Up, r.Redirects, r.Err)
+		}
+		if r.ConnectionReused != reused {
+			t.Errorf("check %v connection_reused is %v, expected %v", n+1, r.ConnectionReused, reused)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+//ICMP represents a check type of unprivileged icmp echo requests
+const ICMP = "icmp"
+
+const (
+	defaultPingCount    = 3
+	defaultPingInterval = 200 * time.Millisecond
+	defaultPingTimeout  = time.Second
+	protocolICMP        = 1
+	protocolICMPv6      = 58
+)
+
+func init() {
+	RegisterChecker(ICMP, newICMPChecker)
+}
+
+//icmpChecker uses datagram icmp sockets, which do not require root on linux
+//if the group updog runs as is in net.ipv4.ping_group_range
+type icmpChecker struct {
+	Count        int        `json:"count"`
+	PingInterval Interval   `json:"ping_interval"`
+	PingTimeout  Interval   `json:"ping_timeout"`
+	WarnLoss     *Threshold `json:"warn_loss,omitempty"`
+	CriticalLoss *Threshold `json:"critical_loss"`
+}
+
+func newICMPChecker(options json.RawMessage) (Checker, error) {
+	c := &icmpChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Count < 0 {
+		return nil, fmt.Errorf("invalid count: %v", c.Count)
+	}
+	if c.Count == 0 {
+		c.Count = defaultPingCount
+	}
+	if c.PingInterval == 0 {
+		c.PingInterval = Interval(defaultPingInterval)
+	}
+	if c.PingTimeout == 0 {
+		c.PingTimeout = Interval(defaultPingTimeout)
+	}
+	if c.CriticalLoss == nil {
+		c.CriticalLoss = &Threshold{Op: ">=", Value: 100}
+	}
+	return c, nil
+}
+
+func (c *icmpChecker) Check(ctx context.Context, address string) CheckResult {
+	host, _ := splitAddress(address, "")
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if len(ips) == 0 {
+		return CheckResult{Err: fmt.Errorf("no addresses for %v", host)}
+	}
+	ip := ips[0].IP
+	for _, a := range ips {
+		if a.IP.To4() != nil {
+			ip = a.IP
+			break
+		}
+	}
+
+	network, proto, echoType := "udp6", protocolICMPv6, icmp.Type(ipv6.ICMPTypeEchoRequest)
+	if ip.To4() != nil {
+		network, proto, echoType = "udp4", protocolICMP, ipv4.ICMPTypeEcho
+	}
+	conn, err := icmp.ListenPacket(network, "")
+	if err != nil {
+		if se, ok := err.(*os.SyscallError); ok && (se.Err == syscall.EACCES || se.Err == syscall.EPERM) {
+			err = fmt.Errorf("%v, check net.ipv4.ping_group_range", err)
+		}
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+
+	var rtts []time.Duration
+	dst := &net.UDPAddr{IP: ip}
+	buf := make([]byte, 1500)
+	for seq := 1; seq <= c.Count && ctx.Err() == nil; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+				continue
+			case <-time.After(time.Duration(c.PingInterval)):
+			}
+		}
+		rtt, err := c.ping(ctx, conn, dst, echoType, proto, seq, buf)
+		if err != nil {
+			continue
+		}
+		rtts = append(rtts, rtt)
+	}
+
+	loss := 100 * float64(c.Count-len(rtts)) / float64(c.Count)
+	r := CheckResult{
+		Up:     true,
+		Status: fmt.Sprintf("%v/%v received", len(rtts), c.Count),
+		Values: map[string]float64{"packet_loss": loss},
+	}
+	if len(rtts) > 0 {
+		min, max, sum := rtts[0], rtts[0], time.Duration(0)
+		for _, rtt := range rtts {
+			if rtt < min {
+				min = rtt
+			}
+			if rtt > max {
+				max = rtt
+			}
+			sum += rtt
+		}
+		r.Values["rtt_min"] = min.Seconds() * 1e3
+		r.Values["rtt_avg"] = (sum / time.Duration(len(rtts))).Seconds() * 1e3
+		r.Values["rtt_max"] = max.Seconds() * 1e3
+	}
+	switch {
+	case c.CriticalLoss.Breached(loss):
+		r.Up = false
+		r.Err = fmt.Errorf("%.0f%% packet loss", loss)
+	case c.WarnLoss.Breached(loss):
+		r.degrade(fmt.Sprintf("%.0f%% packet loss", loss))
+	}
+	return r
+}
+
+//ping sends one echo request, and waits for the reply with the same sequence number
+func (c *icmpChecker) ping(ctx context.Context, conn *icmp.PacketConn, dst net.Addr, echoType icmp.Type, proto, seq int, buf []byte) (time.Duration, error) {
+	// the kernel sets the id to the local port of datagram icmp sockets
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{Seq: seq, Data: []byte("updog")},
+	}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(time.Duration(c.PingTimeout))
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	err = conn.SetReadDeadline(deadline)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	_, err = conn.WriteTo(b, dst)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq &&
+			(reply.Type == ipv4.ICMPTypeEchoReply || reply.Type == ipv6.ICMPTypeEchoReply) {
+			return time.Since(start), nil
+		}
+	}
+}
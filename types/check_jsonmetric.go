@@ -0,0 +1,207 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TrilliumIT/updog/utils"
+)
+
+//JSONMetric represents a check type of comparing values in a json document to thresholds
+const JSONMetric = "json_metric"
+
+func init() {
+	RegisterChecker(JSONMetric, newJSONMetricChecker)
+}
+
+//jsonMetric selects a value from the document, such as beans[0].NumDeadDataNodes
+type jsonMetric struct {
+	Name     string `json:"name,omitempty"`
+	Selector string `json:"selector"`
+	thresholds
+	path []interface{}
+}
+
+type jsonMetricChecker struct {
+	Path     string        `json:"path,omitempty"`
+	Metrics  []*jsonMetric `json:"metrics"`
+	HTTPOpts *HTTPOpts     `json:"http_options"`
+	client   *http.Client
+}
+
+func newJSONMetricChecker(options json.RawMessage) (Checker, error) {
+	c := &jsonMetricChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Metrics) == 0 {
+		return nil, errors.New("json_metric check requires metrics")
+	}
+	for _, m := range c.Metrics {
+		m.path, err = parseSelector(m.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if m.Name == "" {
+			m.Name = m.Selector
+			if k, ok := m.path[len(m.path)-1].(string); ok {
+				m.Name = k
+			}
+		}
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if c.HTTPOpts.HTTPMethod == "" {
+		c.HTTPOpts.HTTPMethod = "GET"
+	}
+	err = c.HTTPOpts.compile()
+	if err != nil {
+		return nil, err
+	}
+	c.client = newHTTPClient(c.HTTPOpts)
+	return c, nil
+}
+
+func (c *jsonMetricChecker) Check(ctx context.Context, address string) CheckResult {
+	opts := c.HTTPOpts
+	req, err := opts.newRequest(ctx, strings.TrimSuffix(address, "/")+c.Path)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer utils.DiscardCloseBody(resp.Body)
+	err = opts.checkStatus(resp)
+	if err != nil {
+		return CheckResult{Err: err, StatusCode: resp.StatusCode}
+	}
+	body, err := opts.readBody(resp)
+	if err != nil {
+		return CheckResult{Err: err, StatusCode: resp.StatusCode}
+	}
+	var doc interface{}
+	err = json.Unmarshal(body, &doc)
+	if err != nil {
+		return CheckResult{Err: fmt.Errorf("invalid json: %v", err), StatusCode: resp.StatusCode}
+	}
+
+	r := CheckResult{Up: true, Values: make(map[string]float64), StatusCode: resp.StatusCode}
+	var status []string
+	values := make(map[*jsonMetric]float64, len(c.Metrics))
+	for _, m := range c.Metrics {
+		v, err := selectValue(doc, m.path)
+		if err != nil {
+			r.Up = false
+			r.Err = fmt.Errorf("%v: %v", m.Name, err)
+			continue
+		}
+		values[m] = v
+		status = append(status, fmt.Sprintf("%v=%v", m.Name, v))
+	}
+	r.Status = strings.Join(status, " ")
+	// the thresholds are applied after the status is set, so the warnings follow the values
+	for _, m := range c.Metrics {
+		if v, ok := values[m]; ok {
+			m.apply(&r, m.Name, v)
+		}
+	}
+	return r
+}
+
+//parseSelector parses a selector such as $.beans[0].NumDeadDataNodes or
+//beans[0]["tag.Hostname"] into a list of string keys and int indexes
+func parseSelector(sel string) ([]interface{}, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(sel), "$")
+	var path []interface{}
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+		case '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid selector %q", sel)
+			}
+			k := s[1:end]
+			s = s[end+1:]
+			if uq, err := strconv.Unquote(strings.Replace(k, "'", "\"", -1)); err == nil {
+				path = append(path, uq)
+				continue
+			}
+			i, err := strconv.Atoi(k)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q", sel)
+			}
+			path = append(path, i)
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			path = append(path, s[:end])
+			s = s[end:]
+		}
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid selector %q", sel)
+	}
+	return path, nil
+}
+
+//selectNode walks the decoded json document and returns the node at the path
+func selectNode(doc interface{}, path []interface{}) (interface{}, error) {
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			o, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not an object", k)
+			}
+			if doc, ok = o[k]; !ok {
+				return nil, fmt.Errorf("%q not found", k)
+			}
+		case int:
+			a, ok := doc.([]interface{})
+			if !ok || k < 0 || k >= len(a) {
+				return nil, fmt.Errorf("index %v not found", k)
+			}
+			doc = a[k]
+		}
+	}
+	return doc, nil
+}
+
+//selectValue walks the decoded json document and returns the numeric value at the path
+func selectValue(doc interface{}, path []interface{}) (float64, error) {
+	doc, err := selectNode(doc, path)
+	if err != nil {
+		return 0, err
+	}
+	switch v := doc.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		return boolValue(v), nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		// NaN and Inf parse, but can be neither compared nor sent on
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("value %q is not a finite number", v)
+		}
+		return f, nil
+	}
+	return 0, errors.New("value is not a number")
+}
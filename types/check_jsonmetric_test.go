@@ -0,0 +1,45 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSelectValue(t *testing.T) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{"a": {"n": 1.5, "s": "2", "b": true, "nan": "NaN", "inf": "-Inf", "big": "1e400", "o": {}}, "l": [3]}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		selector string
+		value    float64
+		invalid  bool
+	}{
+		{"a.n", 1.5, false},
+		{"a.s", 2, false},
+		{"a.b", 1, false},
+		{"l[0]", 3, false},
+		{"a.nan", 0, true},
+		{"a.inf", 0, true},
+		{"a.big", 0, true},
+		{"a.o", 0, true},
+		{"a.missing", 0, true},
+	}
+	for _, tt := range tests {
+		path, err := parseSelector(tt.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := selectValue(doc, path)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("%v is %v, expected an error", tt.selector, v)
+			}
+			continue
+		}
+		if err != nil || v != tt.value {
+			t.Errorf("%v is %v, %v, expected %v", tt.selector, v, err, tt.value)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Memcached represents a check type of the memcached text protocol
+const Memcached = "memcached"
+
+//memcachedStats are the stats recorded as values
+var memcachedStats = map[string]bool{
+	"uptime":           true,
+	"curr_connections": true,
+	"curr_items":       true,
+	"bytes":            true,
+	"limit_maxbytes":   true,
+	"evictions":        true,
+	"get_hits":         true,
+	"get_misses":       true,
+}
+
+func init() {
+	RegisterChecker(Memcached, newMemcachedChecker)
+}
+
+type memcachedChecker struct{}
+
+func newMemcachedChecker(options json.RawMessage) (Checker, error) {
+	return &memcachedChecker{}, nil
+}
+
+func (c *memcachedChecker) Check(ctx context.Context, address string) CheckResult {
+	conn, err := dialTCP(ctx, address, nil)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	_, err = rw.WriteString("version\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "VERSION ") {
+		return CheckResult{Err: fmt.Errorf("version returned %q", line)}
+	}
+	r := CheckResult{Up: true, Status: line, Values: make(map[string]float64)}
+
+	_, err = rw.WriteString("stats\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	for {
+		line, err = rw.ReadString('\n')
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		f := strings.Fields(line)
+		if len(f) == 1 && f[0] == "END" {
+			break
+		}
+		if len(f) != 3 || f[0] != "STAT" {
+			return CheckResult{Err: fmt.Errorf("stats returned %q", strings.TrimSpace(line))}
+		}
+		if !memcachedStats[f[1]] {
+			continue
+		}
+		if v, err := strconv.ParseFloat(f[2], 64); err == nil {
+			r.Values[f[1]] = v
+		}
+	}
+	return r
+}
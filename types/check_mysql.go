@@ -0,0 +1,101 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+//MySQL represents a check type of the mysql protocol
+const MySQL = "mysql"
+
+const mysqlProtocolVersion = 10
+
+func init() {
+	RegisterChecker(MySQL, newMySQLChecker)
+}
+
+type mysqlChecker struct {
+	sqlOpts
+}
+
+func newMySQLChecker(options json.RawMessage) (Checker, error) {
+	c := &mysqlChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.User == "" {
+		c.User = "updog"
+	}
+	return c, nil
+}
+
+func (c *mysqlChecker) Check(ctx context.Context, address string) CheckResult {
+	if !c.login() {
+		return c.handshake(ctx, address)
+	}
+	pw, err := c.password()
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = pw
+	cfg.Net = "tcp"
+	cfg.Addr = address
+	cfg.DBName = c.Database
+	if t, ok := connectTimeout(ctx); ok {
+		cfg.Timeout, cfg.ReadTimeout, cfg.WriteTimeout = t, t, t
+	}
+	return sqlCheck(ctx, "mysql", cfg.FormatDSN(), &c.sqlOpts, "SELECT @@read_only")
+}
+
+//handshake reads the initial handshake packet the server sends on connect
+func (c *mysqlChecker) handshake(ctx context.Context, address string) CheckResult {
+	conn, err := dialTCP(ctx, address, nil)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+
+	head := make([]byte, 4)
+	_, err = io.ReadFull(conn, head)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	n := int(head[0]) | int(head[1])<<8 | int(head[2])<<16
+	if n == 0 || n > 1<<16 {
+		return CheckResult{Err: fmt.Errorf("invalid packet length %v", n)}
+	}
+	payload := make([]byte, n)
+	_, err = io.ReadFull(conn, payload)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+
+	switch payload[0] {
+	case mysqlProtocolVersion:
+		version := payload[1:]
+		if i := bytes.IndexByte(version, 0); i >= 0 {
+			version = version[:i]
+		}
+		return CheckResult{Up: true, Status: string(version)}
+	case 0xff:
+		// error packet: header, 2 byte code, then the message
+		if len(payload) > 3 {
+			msg := payload[3:]
+			if msg[0] == '#' && len(msg) > 6 {
+				msg = msg[6:]
+			}
+			return CheckResult{Err: errors.New(string(msg))}
+		}
+		return CheckResult{Err: errors.New("mysql error")}
+	}
+	return CheckResult{Err: fmt.Errorf("unsupported protocol version %v", payload[0])}
+}
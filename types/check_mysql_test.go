@@ -0,0 +1,82 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+//mysqlStub sends the payload as the first packet, as a server does on connect
+func mysqlStub(payload []byte) func(net.Conn) {
+	return func(conn net.Conn) {
+		n := len(payload)
+		_, _ = conn.Write(append([]byte{byte(n), byte(n >> 8), byte(n >> 16), 0}, payload...))
+		stall(conn)
+	}
+}
+
+func TestMySQLHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		up      bool
+		status  string
+		err     string
+	}{
+		{
+			"handshake",
+			append([]byte("\x0a5.7.22-log\x00"), 1, 0, 0, 0, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 0),
+			true, "5.7.22-log", "",
+		},
+		{
+			"error",
+			append([]byte{0xff, 0x6a, 0x04}, "Host '10.0.0.9' is not allowed to connect to this MySQL server"...),
+			false, "", "Host '10.0.0.9' is not allowed to connect to this MySQL server",
+		},
+		{
+			"error with sql state",
+			append([]byte{0xff, 0x10, 0x04}, "#08004Too many connections"...),
+			false, "", "Too many connections",
+		},
+		{"empty error", []byte{0xff, 0x10, 0x04}, false, "", "mysql error"},
+		{"old protocol", []byte("\x095.0\x00"), false, "", "unsupported protocol version 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, stop := startStub(t, mysqlStub(tt.payload))
+			defer stop()
+			c, err := newMySQLChecker(json.RawMessage(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			r := c.Check(ctx, address)
+			if r.Up != tt.up {
+				t.Errorf("up is %v, expected %v: %v", r.Up, tt.up, r.Err)
+			}
+			if r.Status != tt.status {
+				t.Errorf("status is %q, expected %q", r.Status, tt.status)
+			}
+			if tt.err != "" && (r.Err == nil || r.Err.Error() != tt.err) {
+				t.Errorf("error is %v, expected %v", r.Err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMySQLStalled(t *testing.T) {
+	c, err := newMySQLChecker(json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStalled(t, c)
+}
+
+func TestMySQLLoginStalled(t *testing.T) {
+	c, cleanup := newLoginChecker(t, newMySQLChecker)
+	defer cleanup()
+	checkStalled(t, c)
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq" //postgres driver
+)
+
+//Postgres represents a check type of the postgresql protocol
+const Postgres = "postgres"
+
+const pgProtocolVersion = 196608
+
+func init() {
+	RegisterChecker(Postgres, newPostgresChecker)
+}
+
+type postgresChecker struct {
+	sqlOpts
+	SSLMode string `json:"sslmode,omitempty"`
+}
+
+func newPostgresChecker(options json.RawMessage) (Checker, error) {
+	c := &postgresChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.User == "" {
+		c.User = "updog"
+	}
+	if c.Database == "" {
+		c.Database = c.User
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = "disable"
+	}
+	return c, nil
+}
+
+func (c *postgresChecker) Check(ctx context.Context, address string) CheckResult {
+	if !c.login() {
+		return c.startup(ctx, address)
+	}
+	pw, err := c.password()
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	params := url.Values{"sslmode": {c.SSLMode}}
+	if t, ok := connectTimeout(ctx); ok {
+		// connect_timeout is in whole seconds, and covers the dial and startup
+		params.Set("connect_timeout", strconv.Itoa(int(math.Ceil(t.Seconds()))))
+	}
+	dsn := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, pw),
+		Host:     address,
+		Path:     "/" + c.Database,
+		RawQuery: params.Encode(),
+	}
+	return sqlCheck(ctx, "postgres", dsn.String(), &c.sqlOpts, "SELECT pg_is_in_recovery()")
+}
+
+//startup sends a startup message, and expects the server to request authentication
+func (c *postgresChecker) startup(ctx context.Context, address string) CheckResult {
+	conn, err := dialTCP(ctx, address, nil)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+
+	var msg bytes.Buffer
+	_ = binary.Write(&msg, binary.BigEndian, int32(pgProtocolVersion))
+	for _, p := range []string{"user", c.User, "database", c.Database, ""} {
+		msg.WriteString(p)
+		msg.WriteByte(0)
+	}
+	l := make([]byte, 4)
+	binary.BigEndian.PutUint32(l, uint32(msg.Len()+4))
+	_, err = conn.Write(append(l, msg.Bytes()...))
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+
+	head := make([]byte, 5)
+	_, err = io.ReadFull(conn, head)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	n := int(binary.BigEndian.Uint32(head[1:])) - 4
+	if n < 0 || n > 1<<16 {
+		return CheckResult{Err: fmt.Errorf("invalid message length %v", n)}
+	}
+	body := make([]byte, n)
+	_, err = io.ReadFull(conn, body)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+
+	switch head[0] {
+	case 'R':
+		return CheckResult{Up: true}
+	case 'E':
+		return CheckResult{Err: pgError(body)}
+	}
+	return CheckResult{Err: fmt.Errorf("unexpected message type %q", head[0])}
+}
+
+//pgError formats the severity, code and message fields of an ErrorResponse
+func pgError(body []byte) error {
+	fields := make(map[byte]string)
+	for _, f := range bytes.Split(body, []byte{0}) {
+		if len(f) > 1 {
+			fields[f[0]] = string(f[1:])
+		}
+	}
+	if fields['M'] == "" {
+		return errors.New("postgres error")
+	}
+	return errors.New(strings.TrimSpace(fmt.Sprintf("%v %v %v", fields['S'], fields['C'], fields['M'])))
+}
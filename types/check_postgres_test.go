@@ -0,0 +1,87 @@
+package types
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+//pgStub reads a startup message, and replies with a message of the type and body
+func pgStub(t *testing.T, msgType byte, body []byte) func(net.Conn) {
+	return func(conn net.Conn) {
+		head := make([]byte, 8)
+		_, err := io.ReadFull(conn, head)
+		if err != nil {
+			return
+		}
+		if v := binary.BigEndian.Uint32(head[4:]); v != pgProtocolVersion {
+			t.Errorf("protocol version is %v", v)
+		}
+		rest := make([]byte, binary.BigEndian.Uint32(head[:4])-8)
+		_, err = io.ReadFull(conn, rest)
+		if err != nil {
+			return
+		}
+		msg := []byte{msgType, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(msg[1:], uint32(len(body)+4))
+		_, _ = conn.Write(append(msg, body...))
+		stall(conn)
+	}
+}
+
+func TestPostgresStartup(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgType byte
+		body    []byte
+		up      bool
+		err     string
+	}{
+		{"cleartext password requested", 'R', []byte{0, 0, 0, 3}, true, ""},
+		{"md5 password requested", 'R', []byte{0, 0, 0, 5, 1, 2, 3, 4}, true, ""},
+		{
+			"error response", 'E',
+			[]byte("SFATAL\x00C28000\x00Mno pg_hba.conf entry for host\x00\x00"),
+			false, "FATAL 28000 no pg_hba.conf entry for host",
+		},
+		{"error response without message", 'E', []byte("SFATAL\x00\x00"), false, "postgres error"},
+		{"unexpected message", 'X', nil, false, `unexpected message type 'X'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, stop := startStub(t, pgStub(t, tt.msgType, tt.body))
+			defer stop()
+			c, err := newPostgresChecker(json.RawMessage(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			r := c.Check(ctx, address)
+			if r.Up != tt.up {
+				t.Errorf("up is %v, expected %v: %v", r.Up, tt.up, r.Err)
+			}
+			if tt.err != "" && (r.Err == nil || r.Err.Error() != tt.err) {
+				t.Errorf("error is %v, expected %v", r.Err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPostgresStalled(t *testing.T) {
+	c, err := newPostgresChecker(json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStalled(t, c)
+}
+
+func TestPostgresLoginStalled(t *testing.T) {
+	c, cleanup := newLoginChecker(t, newPostgresChecker)
+	defer cleanup()
+	checkStalled(t, c)
+}
@@ -0,0 +1,268 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TrilliumIT/updog/utils"
+)
+
+//PrometheusMetric represents a check type of comparing values scraped from a prometheus endpoint to thresholds
+const PrometheusMetric = "prometheus_metric"
+
+const defaultPrometheusPath = "/metrics"
+
+func init() {
+	RegisterChecker(PrometheusMetric, newPrometheusChecker)
+}
+
+//promMetric selects a single series by name and label matchers, such as up_replicas{cluster="a"}
+type promMetric struct {
+	Name     string `json:"name,omitempty"`
+	Selector string `json:"selector"`
+	thresholds
+	metric string
+	labels map[string]string
+}
+
+//matches returns true if the series has the metric name and all of the selected labels
+func (m *promMetric) matches(name string, labels map[string]string) bool {
+	if name != m.metric {
+		return false
+	}
+	for k, v := range m.labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type prometheusChecker struct {
+	Path     string        `json:"path,omitempty"`
+	Metrics  []*promMetric `json:"metrics"`
+	HTTPOpts *HTTPOpts     `json:"http_options"`
+	client   *http.Client
+}
+
+func newPrometheusChecker(options json.RawMessage) (Checker, error) {
+	c := &prometheusChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Metrics) == 0 {
+		return nil, errors.New("prometheus_metric check requires metrics")
+	}
+	for _, m := range c.Metrics {
+		m.metric, m.labels, err = parseSeries(m.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", m.Selector, err)
+		}
+		if m.Name == "" {
+			m.Name = m.metric
+		}
+	}
+	if c.Path == "" {
+		c.Path = defaultPrometheusPath
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if c.HTTPOpts.HTTPMethod == "" {
+		c.HTTPOpts.HTTPMethod = "GET"
+	}
+	err = c.HTTPOpts.compile()
+	if err != nil {
+		return nil, err
+	}
+	c.client = newHTTPClient(c.HTTPOpts)
+	return c, nil
+}
+
+func (c *prometheusChecker) Check(ctx context.Context, address string) CheckResult {
+	opts := c.HTTPOpts
+	req, err := opts.newRequest(ctx, strings.TrimSuffix(address, "/")+c.Path)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer utils.DiscardCloseBody(resp.Body)
+	err = opts.checkStatus(resp)
+	if err != nil {
+		return CheckResult{Err: err, StatusCode: resp.StatusCode}
+	}
+	body, err := opts.readBody(resp)
+	if err != nil {
+		return CheckResult{Err: err, StatusCode: resp.StatusCode}
+	}
+
+	found := make([][]float64, len(c.Metrics))
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	sc.Buffer(nil, len(body)+1)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name, labels, v, err := parseSample(line)
+		if err != nil {
+			return CheckResult{Err: err, StatusCode: resp.StatusCode}
+		}
+		for i, m := range c.Metrics {
+			if m.matches(name, labels) {
+				found[i] = append(found[i], v)
+			}
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return CheckResult{Err: fmt.Errorf("error reading metrics: %v", err), StatusCode: resp.StatusCode}
+	}
+
+	r := CheckResult{Up: true, Values: make(map[string]float64), StatusCode: resp.StatusCode}
+	var status []string
+	values := make(map[*promMetric]float64, len(c.Metrics))
+	for i, m := range c.Metrics {
+		switch len(found[i]) {
+		case 0:
+			r.Up = false
+			r.Err = fmt.Errorf("%v: no series matches %v", m.Name, m.Selector)
+			continue
+		case 1:
+		default:
+			r.Up = false
+			r.Err = fmt.Errorf("%v: %v series match %v", m.Name, len(found[i]), m.Selector)
+			continue
+		}
+		v := found[i][0]
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// a value which is not finite can be neither compared nor sent on
+			r.Up = false
+			r.Err = fmt.Errorf("%v: %v is %v", m.Name, m.Selector, v)
+			continue
+		}
+		values[m] = v
+		status = append(status, fmt.Sprintf("%v=%v", m.Name, v))
+	}
+	r.Status = strings.Join(status, " ")
+	// the thresholds are applied after the status is set, so the warnings follow the values
+	for _, m := range c.Metrics {
+		if v, ok := values[m]; ok {
+			m.apply(&r, m.Name, v)
+		}
+	}
+	return r
+}
+
+//parseSeries parses a metric name and optional labels, such as up_replicas{cluster="a"}
+func parseSeries(s string) (string, map[string]string, error) {
+	s = strings.TrimSpace(s)
+	name := s
+	labels := make(map[string]string)
+	if i := strings.IndexByte(s, '{'); i >= 0 {
+		name = strings.TrimSpace(s[:i])
+		rest, err := parseLabels(s[i+1:], labels)
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return "", nil, fmt.Errorf("unexpected %q after labels", rest)
+		}
+	}
+	if name == "" {
+		return "", nil, errors.New("missing metric name")
+	}
+	return name, labels, nil
+}
+
+//parseSample parses a line of the text exposition format, such as
+//http_requests_total{method="post",code="200"} 1027 1395066363000
+func parseSample(line string) (string, map[string]string, float64, error) {
+	labels := make(map[string]string)
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return "", nil, 0, fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:end], line[end:]
+	if rest[0] == '{' {
+		var err error
+		rest, err = parseLabels(rest[1:], labels)
+		if err != nil {
+			return "", nil, 0, fmt.Errorf("invalid sample %q: %v", line, err)
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return "", nil, 0, fmt.Errorf("invalid sample %q", line)
+	}
+	v, err := parsePromFloat(fields[0])
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("invalid sample %q: %v", line, err)
+	}
+	return name, labels, v, nil
+}
+
+//parseLabels parses label pairs into labels, up to and including the closing brace,
+//and returns the rest of the string
+func parseLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return "", errors.New("unterminated labels")
+		}
+		if s[0] == '}' {
+			return s[1:], nil
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return "", errors.New("label missing value")
+		}
+		k := strings.TrimSpace(s[:eq])
+		s = strings.TrimSpace(s[eq+1:])
+		if s == "" || s[0] != '"' {
+			return "", fmt.Errorf("label %v value is not quoted", k)
+		}
+		var v strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					v.WriteByte('\n')
+					continue
+				}
+			}
+			v.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("label %v value is not terminated", k)
+		}
+		labels[k] = v.String()
+		s = s[i+1:]
+	}
+}
+
+//parsePromFloat parses a sample value, including NaN and +Inf or -Inf
+func parsePromFloat(s string) (float64, error) {
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "+Inf", "Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseSample(t *testing.T) {
+	tests := []struct {
+		line   string
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		{`up 1`, "up", map[string]string{}, 1},
+		{`up{} 1`, "up", map[string]string{}, 1},
+		{"up\t0", "up", map[string]string{}, 0},
+		{`http_requests_total{method="post",code="200"} 1027 1395066363000`, "http_requests_total", map[string]string{"method": "post", "code": "200"}, 1027},
+		{`http_requests_total{method="post",code="200",} 3`, "http_requests_total", map[string]string{"method": "post", "code": "200"}, 3},
+		{`m{ a = "1" , b="2" }   4.5`, "m", map[string]string{"a": "1", "b": "2"}, 4.5},
+		{`msdos_file_access_time_seconds{path="C:\\DIR\\FILE.TXT",error="Cannot find file:\n\"FILE.TXT\""} 1.458255915e9`, "msdos_file_access_time_seconds", map[string]string{"path": `C:\DIR\FILE.TXT`, "error": "Cannot find file:\n\"FILE.TXT\""}, 1.458255915e9},
+		{`m{selector="a{b=\"c\"}, d"} 2`, "m", map[string]string{"selector": `a{b="c"}, d`}, 2},
+		{`m{le="+Inf"} 7`, "m", map[string]string{"le": "+Inf"}, 7},
+		{`m +Inf`, "m", map[string]string{}, math.Inf(1)},
+		{`m -Inf`, "m", map[string]string{}, math.Inf(-1)},
+		{`m -0.5e-3 -1`, "m", map[string]string{}, -0.0005},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			name, labels, v, err := parseSample(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.name || !reflect.DeepEqual(labels, tt.labels) || v != tt.value {
+				t.Errorf("parsed %v %v %v, expected %v %v %v", name, labels, v, tt.name, tt.labels, tt.value)
+			}
+		})
+	}
+
+	_, _, v, err := parseSample(`m{a="b"} NaN`)
+	if err != nil || !math.IsNaN(v) {
+		t.Errorf("NaN parsed as %v, %v", v, err)
+	}
+}
+
+func TestParseSampleInvalid(t *testing.T) {
+	for _, line := range []string{
+		`up`,
+		`up{} `,
+		`up 1 2 3`,
+		`up one`,
+		`up{a="b" 1`,
+		`up{a="b} 1`,
+		`up{a=b} 1`,
+		`up{a} 1`,
+	} {
+		_, _, _, err := parseSample(line)
+		if err == nil {
+			t.Errorf("expected error parsing %q", line)
+		}
+	}
+}
+
+func TestParseSeries(t *testing.T) {
+	tests := []struct {
+		selector string
+		name     string
+		labels   map[string]string
+		invalid  bool
+	}{
+		{"up", "up", map[string]string{}, false},
+		{` up_replicas{cluster="a"} `, "up_replicas", map[string]string{"cluster": "a"}, false},
+		{`m{a="1",b="x\"y"}`, "m", map[string]string{"a": "1", "b": `x"y`}, false},
+		{``, "", nil, true},
+		{`{a="1"}`, "", nil, true},
+		{`m{a="1"} extra`, "", nil, true},
+		{`m{a="1"`, "", nil, true},
+	}
+	for _, tt := range tests {
+		name, labels, err := parseSeries(tt.selector)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("expected error parsing %q", tt.selector)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("error parsing %q: %v", tt.selector, err)
+			continue
+		}
+		if name != tt.name || !reflect.DeepEqual(labels, tt.labels) {
+			t.Errorf("parsed %q as %v %v, expected %v %v", tt.selector, name, labels, tt.name, tt.labels)
+		}
+	}
+}
+
+const testMetrics = `# HELP replicas_up The replicas which are up
+# TYPE replicas_up gauge
+replicas_up{cluster="a"} 3 1395066363000
+replicas_up{cluster="b"} 1
+# HELP queue_depth The messages waiting
+# TYPE queue_depth gauge
+queue_depth 42
+
+ratio NaN
+overflow +Inf
+`
+
+func TestPrometheusCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken/metrics" {
+			fmt.Fprintln(w, `replicas_up{cluster="a" 3`)
+			return
+		}
+		fmt.Fprint(w, testMetrics)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		options  string
+		up       bool
+		degraded bool
+		values   map[string]float64
+	}{
+		{"ok", `{"metrics": [{"selector": "replicas_up{cluster=\"a\"}", "critical": "< 2"}]}`, true, false, map[string]float64{"replicas_up": 3}},
+		{"named", `{"metrics": [{"name": "b", "selector": "replicas_up{cluster=\"b\"}"}, {"selector": "queue_depth"}]}`, true, false, map[string]float64{"b": 1, "queue_depth": 42}},
+		{"warn", `{"metrics": [{"selector": "replicas_up{cluster=\"b\"}", "warn": "< 2", "critical": "< 1"}]}`, true, true, map[string]float64{"replicas_up": 1}},
+		{"critical", `{"metrics": [{"selector": "queue_depth", "warn": 10, "critical": 40}]}`, false, false, map[string]float64{"queue_depth": 42}},
+		{"no series", `{"metrics": [{"selector": "replicas_up{cluster=\"c\"}"}]}`, false, false, map[string]float64{}},
+		{"several series", `{"metrics": [{"selector": "replicas_up"}]}`, false, false, map[string]float64{}},
+		{"one of several missing", `{"metrics": [{"selector": "queue_depth"}, {"selector": "missing"}]}`, false, false, map[string]float64{"queue_depth": 42}},
+		{"malformed", `{"path": "/broken/metrics", "metrics": [{"selector": "replicas_up"}]}`, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newPrometheusChecker(json.RawMessage(tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := c.Check(context.Background(), srv.URL)
+			if r.Up != tt.up || r.Degraded != tt.degraded {
+				t.Errorf("up is %v and degraded %v, expected %v and %v: %v", r.Up, r.Degraded, tt.up, tt.degraded, r.Err)
+			}
+			if r.Up && r.Err != nil {
+				t.Errorf("unexpected error %v", r.Err)
+			}
+			if !r.Up && r.Err == nil {
+				t.Error("down without an error")
+			}
+			if !reflect.DeepEqual(r.Values, tt.values) {
+				t.Errorf("values are %v, expected %v", r.Values, tt.values)
+			}
+		})
+	}
+}
+
+func TestPrometheusCheckWarn(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testMetrics)
+	}))
+	defer srv.Close()
+	c, err := newPrometheusChecker(json.RawMessage(`{"metrics": [{"name": "b", "selector": "replicas_up{cluster=\"b\"}", "warn": "< 2"}, {"selector": "queue_depth", "warn": 40}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.Check(context.Background(), srv.URL)
+	if !r.Up || !r.Degraded || r.Err != nil {
+		t.Errorf("up is %v and degraded %v: %v", r.Up, r.Degraded, r.Err)
+	}
+	if status := "b=1 queue_depth=42, b is 1, warn < 2, queue_depth is 42, warn > 40"; r.Status != status {
+		t.Errorf("status is %q, expected %q", r.Status, status)
+	}
+}
+
+func TestPrometheusCheckNotFinite(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testMetrics)
+	}))
+	defer srv.Close()
+	for _, selector := range []string{"ratio", "overflow"} {
+		c, err := newPrometheusChecker(json.RawMessage(`{"metrics": [{"selector": "queue_depth"}, {"selector": "` + selector + `", "critical": "> 0.5"}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := c.Check(context.Background(), srv.URL)
+		if r.Up || r.Err == nil {
+			t.Errorf("%v is up: %v", selector, r.Err)
+		}
+		if _, ok := r.Values[selector]; ok {
+			t.Errorf("%v in values %v", selector, r.Values)
+		}
+		if _, err = json.Marshal(r.Values); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNewPrometheusCheckerInvalid(t *testing.T) {
+	for _, options := range []string{
+		`{}`,
+		`{"metrics": [{"selector": "m{a=\"b\""}]}`,
+		`{"metrics": [{"selector": "m", "warn": "about 5"}]}`,
+	} {
+		_, err := newPrometheusChecker(json.RawMessage(options))
+		if err == nil {
+			t.Errorf("expected error for %v", options)
+		}
+	}
+}
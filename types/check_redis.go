@@ -0,0 +1,183 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+//Redis represents a check type of the redis protocol
+const Redis = "redis"
+
+func init() {
+	RegisterChecker(Redis, newRedisChecker)
+}
+
+type redisChecker struct {
+	PasswordFile string `json:"password_file,omitempty"`
+}
+
+func newRedisChecker(options json.RawMessage) (Checker, error) {
+	c := &redisChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *redisChecker) Check(ctx context.Context, address string) CheckResult {
+	conn, err := dialTCP(ctx, address, nil)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if c.PasswordFile != "" {
+		var pw []byte
+		pw, err = ioutil.ReadFile(c.PasswordFile)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		_, err = redisCommand(rw, "AUTH", strings.TrimSpace(string(pw)))
+		if err != nil {
+			return CheckResult{Err: fmt.Errorf("auth failed: %v", err)}
+		}
+	}
+
+	pong, err := redisCommand(rw, "PING")
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if pong != "PONG" {
+		return CheckResult{Err: fmt.Errorf("ping returned %q", pong)}
+	}
+
+	info, err := redisCommand(rw, "INFO", "replication")
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	return parseRedisReplication(info)
+}
+
+//parseRedisReplication reads the role and replication lag from the replication section of INFO
+func parseRedisReplication(info string) CheckResult {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		p := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(p) == 2 {
+			fields[p[0]] = p[1]
+		}
+	}
+
+	r := CheckResult{Up: true, Role: fields["role"], Status: fields["role"], Values: make(map[string]float64)}
+	if v, err := strconv.ParseFloat(fields["connected_slaves"], 64); err == nil {
+		r.Values["connected_slaves"] = v
+	}
+	if fields["role"] != "slave" {
+		redisReplicaLag(fields, r.Values)
+		return r
+	}
+
+	// a replica only knows its own offset, the time since it heard from the master is its lag
+	if v, err := strconv.ParseFloat(fields["master_last_io_seconds_ago"], 64); err == nil {
+		r.Values["master_last_io_seconds_ago"] = v
+	}
+	if fields["master_link_status"] != "up" {
+		r.degrade("master_link_status is " + fields["master_link_status"])
+	}
+	return r
+}
+
+//redisReplicaLag sets the largest lag of the replicas connected to a master, from the
+//slaveN lines such as slave0:ip=10.0.0.2,port=6379,state=online,offset=1234,lag=0
+func redisReplicaLag(fields map[string]string, values map[string]float64) {
+	mo, err := strconv.ParseFloat(fields["master_repl_offset"], 64)
+	if err != nil {
+		return
+	}
+	for k, v := range fields {
+		if !strings.HasPrefix(k, "slave") {
+			continue
+		}
+		if _, err := strconv.Atoi(k[len("slave"):]); err != nil {
+			continue
+		}
+		replica := make(map[string]string)
+		for _, kv := range strings.Split(v, ",") {
+			p := strings.SplitN(kv, "=", 2)
+			if len(p) == 2 {
+				replica[p[0]] = p[1]
+			}
+		}
+		if o, err := strconv.ParseFloat(replica["offset"], 64); err == nil {
+			if lag, ok := values["repl_lag_bytes"]; !ok || mo-o > lag {
+				values["repl_lag_bytes"] = mo - o
+			}
+		}
+		if l, err := strconv.ParseFloat(replica["lag"], 64); err == nil {
+			if lag, ok := values["repl_lag_seconds"]; !ok || l > lag {
+				values["repl_lag_seconds"] = l
+			}
+		}
+	}
+}
+
+//redisCommand sends a command as an array of bulk strings, and reads a simple,
+//integer or bulk string reply
+func redisCommand(rw *bufio.ReadWriter, args ...string) (string, error) {
+	_, err := fmt.Fprintf(rw, "*%d\r\n", len(args))
+	if err != nil {
+		return "", err
+	}
+	for _, a := range args {
+		_, err = fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(a), a)
+		if err != nil {
+			return "", err
+		}
+	}
+	err = rw.Flush()
+	if err != nil {
+		return "", err
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", errors.New(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return "", errors.New("nil reply")
+		}
+		if n > defaultMaxBodySize {
+			return "", fmt.Errorf("bulk reply too large: %v", n)
+		}
+		b := make([]byte, n+2)
+		_, err = io.ReadFull(rw, b)
+		if err != nil {
+			return "", err
+		}
+		return string(b[:n]), nil
+	}
+	return "", fmt.Errorf("unexpected reply %q", line)
+}
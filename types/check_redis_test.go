@@ -0,0 +1,80 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRedisReplication(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     []string
+		role     string
+		degraded bool
+		values   map[string]float64
+	}{
+		{
+			"master with replicas",
+			[]string{
+				"# Replication",
+				"role:master",
+				"connected_slaves:2",
+				"slave0:ip=10.0.0.2,port=6379,state=online,offset=1000,lag=0",
+				"slave1:ip=10.0.0.3,port=6379,state=online,offset=400,lag=3",
+				"master_repl_offset:1200",
+				"repl_backlog_active:1",
+			},
+			"master", false,
+			map[string]float64{"connected_slaves": 2, "repl_lag_bytes": 800, "repl_lag_seconds": 3},
+		},
+		{
+			"master in sync",
+			[]string{
+				"role:master",
+				"connected_slaves:1",
+				"slave0:ip=10.0.0.2,port=6379,state=online,offset=1200,lag=0",
+				"master_repl_offset:1200",
+			},
+			"master", false,
+			map[string]float64{"connected_slaves": 1, "repl_lag_bytes": 0, "repl_lag_seconds": 0},
+		},
+		{
+			"master without replicas",
+			[]string{"role:master", "connected_slaves:0", "master_repl_offset:0"},
+			"master", false,
+			map[string]float64{"connected_slaves": 0},
+		},
+		{
+			"replica",
+			[]string{
+				"role:slave",
+				"master_host:10.0.0.1",
+				"master_link_status:up",
+				"master_last_io_seconds_ago:2",
+				"slave_repl_offset:1200",
+				"connected_slaves:0",
+				"master_repl_offset:1200",
+			},
+			"slave", false,
+			map[string]float64{"connected_slaves": 0, "master_last_io_seconds_ago": 2},
+		},
+		{
+			"replica link down",
+			[]string{"role:slave", "master_link_status:down", "master_last_io_seconds_ago:-1", "connected_slaves:0"},
+			"slave", true,
+			map[string]float64{"connected_slaves": 0, "master_last_io_seconds_ago": -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseRedisReplication(strings.Join(tt.info, "\r\n") + "\r\n")
+			if !r.Up || r.Role != tt.role || r.Degraded != tt.degraded || r.Err != nil {
+				t.Errorf("up %v role %q degraded %v, expected role %q degraded %v: %v", r.Up, r.Role, r.Degraded, tt.role, tt.degraded, r.Err)
+			}
+			if !reflect.DeepEqual(r.Values, tt.values) {
+				t.Errorf("values are %v, expected %v", r.Values, tt.values)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rolePrimary = "primary"
+	roleReplica = "replica"
+)
+
+//sqlOpts are the options shared by the database check types
+type sqlOpts struct {
+	User         string `json:"user,omitempty"`
+	Database     string `json:"database,omitempty"`
+	PasswordFile string `json:"password_file,omitempty"`
+	Query        string `json:"query,omitempty"`
+	ReportRole   bool   `json:"report_role,omitempty"`
+}
+
+//login returns true if the check should authenticate, rather than only complete the handshake
+func (o *sqlOpts) login() bool {
+	return o.PasswordFile != "" || o.Query != "" || o.ReportRole
+}
+
+func (o *sqlOpts) password() (string, error) {
+	if o.PasswordFile == "" {
+		return "", nil
+	}
+	pw, err := ioutil.ReadFile(o.PasswordFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(pw)), nil
+}
+
+//connectTimeout returns the time left before the deadline of the context. The drivers
+//don't use the context to connect, so it is set as their timeouts in the dsn instead.
+func connectTimeout(ctx context.Context) (time.Duration, bool) {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	t := time.Until(dl)
+	if t < time.Millisecond {
+		t = time.Millisecond
+	}
+	return t, true
+}
+
+//sqlError classes the error as a timeout if the deadline of the context has passed,
+//the drivers report timeouts while connecting as bad connections
+func sqlError(ctx context.Context, err error) CheckResult {
+	if ctx.Err() != nil {
+		return CheckResult{Err: &classError{ErrorClassTimeout, err}}
+	}
+	return CheckResult{Err: err}
+}
+
+//sqlCheck logs in using the driver, runs the query and if reporting the role, runs the
+//roleQuery, which returns true if the instance is a replica
+func sqlCheck(ctx context.Context, driver, dsn string, o *sqlOpts, roleQuery string) CheckResult {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		err = db.Close()
+		if err != nil {
+			log.WithError(err).Error("Error closing database")
+		}
+	}()
+
+	query := o.Query
+	if query == "" {
+		query = "SELECT 1"
+	}
+	var v interface{}
+	err = db.QueryRowContext(ctx, query).Scan(&v)
+	if err != nil {
+		return sqlError(ctx, err)
+	}
+	if !o.ReportRole {
+		return CheckResult{Up: true}
+	}
+
+	var replica bool
+	err = db.QueryRowContext(ctx, roleQuery).Scan(&replica)
+	if err != nil {
+		return sqlError(ctx, err)
+	}
+	role := rolePrimary
+	if replica {
+		role = roleReplica
+	}
+	return CheckResult{Up: true, Role: role, Status: role}
+}
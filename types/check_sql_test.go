@@ -0,0 +1,95 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+//startStub accepts connections on a loopback port, handling each with handle. It returns
+//the address, and a func which closes the listener and any connections still open.
+func startStub(t *testing.T, handle func(net.Conn)) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lock sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			lock.Lock()
+			conns = append(conns, conn)
+			lock.Unlock()
+			go handle(conn)
+		}
+	}()
+	return l.Addr().String(), func() {
+		_ = l.Close()
+		lock.Lock()
+		defer lock.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}
+}
+
+//stall reads from the connection without ever replying, like a server which
+//accepts connections but is stuck before its handshake
+func stall(conn net.Conn) {
+	_, _ = io.Copy(ioutil.Discard, conn)
+}
+
+//checkStalled checks a stalled server, and fails if the check is not a timeout which
+//returns soon after the deadline. Postgres timeouts are rounded up to a second.
+func checkStalled(t *testing.T, c Checker) {
+	address, stop := startStub(t, stall)
+	defer stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	done := make(chan CheckResult, 1)
+	go func() { done <- c.Check(ctx, address) }()
+	select {
+	case r := <-done:
+		if r.Up {
+			t.Error("stalled server is up")
+		}
+		if class := classifyError(r.Err); class != ErrorClassTimeout {
+			t.Errorf("error class is %q, expected %q: %v", class, ErrorClassTimeout, r.Err)
+		}
+		t.Logf("stalled server failed after %v: %v", time.Since(start), r.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("check of stalled server did not return")
+	}
+}
+
+//newLoginChecker creates a checker of the check type which logs in with a password file
+func newLoginChecker(t *testing.T, factory CheckerFactory) (Checker, func()) {
+	f, err := ioutil.TempFile("", "updog-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("secret\n")
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, _ := json.Marshal(map[string]string{"password_file": f.Name()})
+	c, err := factory(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, func() { _ = os.Remove(f.Name()) }
+}
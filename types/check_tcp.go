@@ -0,0 +1,71 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//TCPConnect represents a check type of tcp syn/ack
+const TCPConnect = "tcp_connect"
+
+func init() {
+	RegisterChecker(TCPConnect, newTCPConnectChecker)
+}
+
+type tcpConnectChecker struct{}
+
+func newTCPConnectChecker(options json.RawMessage) (Checker, error) {
+	return &tcpConnectChecker{}, nil
+}
+
+func (c *tcpConnectChecker) Check(ctx context.Context, address string) CheckResult {
+	conn, err := dialContext(ctx, "tcp", address)
+	if err == nil {
+		defer func() {
+			err = conn.Close()
+			if err != nil {
+				log.WithError(err).Error("Error closing connection")
+			}
+		}()
+	}
+	return CheckResult{Up: err == nil, Err: err}
+}
+
+//dialTCP connects to the address with the deadline of the context, and completes
+//a tls handshake if tlsConfig is not nil
+func dialTCP(ctx context.Context, address string, tlsConfig *tls.Config) (net.Conn, error) {
+	conn, err := dialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(dl)
+		if err != nil {
+			closeConn(conn)
+			return nil, err
+		}
+	}
+	if tlsConfig == nil {
+		return conn, nil
+	}
+	tconn := tls.Client(conn, tlsConfig)
+	err = tconn.Handshake()
+	if err != nil {
+		closeConn(conn)
+		return nil, &classError{ErrorClassTLS, fmt.Errorf("tls handshake failed: %v", err)}
+	}
+	return tconn, nil
+}
+
+func closeConn(conn io.Closer) {
+	err := conn.Close()
+	if err != nil {
+		log.WithError(err).Error("Error closing connection")
+	}
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+//TCPExpect represents a check type of sending a payload over tcp and matching the reply
+const TCPExpect = "tcp_expect"
+
+const defaultReadBytes = 1024
+
+func init() {
+	RegisterChecker(TCPExpect, newTCPExpectChecker)
+}
+
+//expectOpts match a reply against a string and a regex
+type expectOpts struct {
+	Expect      string `json:"expect,omitempty"`
+	ExpectRegex string `json:"expect_regex,omitempty"`
+	expectRegex *regexp.Regexp
+}
+
+func (o *expectOpts) compile() (err error) {
+	if o.ExpectRegex != "" {
+		o.expectRegex, err = regexp.Compile(o.ExpectRegex)
+		if err != nil {
+			return fmt.Errorf("invalid expect_regex: %v", err)
+		}
+	}
+	return nil
+}
+
+func (o *expectOpts) expects() bool {
+	return o.Expect != "" || o.expectRegex != nil
+}
+
+func (o *expectOpts) matches(reply []byte) bool {
+	if o.Expect != "" && !bytes.Contains(reply, []byte(o.Expect)) {
+		return false
+	}
+	if o.expectRegex != nil && !o.expectRegex.Match(reply) {
+		return false
+	}
+	return true
+}
+
+type tcpExpectChecker struct {
+	Send string `json:"send,omitempty"`
+	expectOpts
+	ReadBytes int       `json:"read_bytes,omitempty"`
+	TLS       bool      `json:"tls,omitempty"`
+	HTTPOpts  *HTTPOpts `json:"http_options,omitempty"`
+	tlsConfig *tls.Config
+}
+
+func newTCPExpectChecker(options json.RawMessage) (Checker, error) {
+	c := &tcpExpectChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	err = c.compile()
+	if err != nil {
+		return nil, err
+	}
+	if c.ReadBytes < 0 {
+		return nil, fmt.Errorf("invalid read_bytes: %v", c.ReadBytes)
+	}
+	if c.ReadBytes == 0 {
+		c.ReadBytes = defaultReadBytes
+	}
+	if c.TLS {
+		if c.HTTPOpts == nil {
+			c.HTTPOpts = &HTTPOpts{}
+		}
+		c.tlsConfig = newTLSConfig(c.HTTPOpts)
+	}
+	return c, nil
+}
+
+func (c *tcpExpectChecker) Check(ctx context.Context, address string) CheckResult {
+	var cfg *tls.Config
+	if c.tlsConfig != nil {
+		cfg = c.tlsConfig.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = serverName(ctx, address)
+		}
+	}
+	conn, err := dialTCP(ctx, address, cfg)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+
+	if c.Send != "" {
+		_, err = conn.Write([]byte(c.Send))
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+	}
+
+	if !c.expects() {
+		return CheckResult{Up: true}
+	}
+
+	// read until the reply matches, or read_bytes is reached, the connection closes or times out
+	reply := make([]byte, 0, c.ReadBytes)
+	buf := make([]byte, c.ReadBytes)
+	for len(reply) < c.ReadBytes {
+		var n int
+		n, err = conn.Read(buf[:c.ReadBytes-len(reply)])
+		reply = append(reply, buf[:n]...)
+		if c.matches(reply) {
+			return CheckResult{Up: true}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil && len(reply) == 0 {
+		return CheckResult{Err: err}
+	}
+	return CheckResult{Err: fmt.Errorf("reply did not match: %q", reply)}
+}
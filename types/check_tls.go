@@ -0,0 +1,117 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+//TLSCert represents a check type of tls certificate expiry and validity
+const TLSCert = "tls_cert"
+
+const defaultTLSWarnDays = 30
+
+func init() {
+	RegisterChecker(TLSCert, newTLSCertChecker)
+}
+
+type tlsCertChecker struct {
+	HTTPOpts   *HTTPOpts `json:"http_options"`
+	ServerName string    `json:"server_name,omitempty"`
+	WarnDays   int       `json:"warn_days"`
+	tlsConfig  *tls.Config
+}
+
+func newTLSCertChecker(options json.RawMessage) (Checker, error) {
+	c := &tlsCertChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if c.WarnDays == 0 {
+		c.WarnDays = defaultTLSWarnDays
+	}
+	if c.WarnDays < 0 {
+		return nil, fmt.Errorf("invalid warn_days: %v", c.WarnDays)
+	}
+	c.tlsConfig = newTLSConfig(c.HTTPOpts)
+	return c, nil
+}
+
+func (c *tlsCertChecker) Check(ctx context.Context, address string) CheckResult {
+	host, port := splitAddress(address, "443")
+	name := c.ServerName
+	if name == "" {
+		name = serverName(ctx, address)
+	}
+
+	// verification is done below, so the chain and hostname can be reported separately
+	cfg := c.tlsConfig.Clone()
+	cfg.InsecureSkipVerify = true
+	cfg.ServerName = name
+	conn, err := dialTCP(ctx, net.JoinHostPort(host, port), cfg)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return CheckResult{Err: errors.New("no peer certificates")}
+	}
+	leaf := certs[0]
+	now := time.Now()
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, chainErr := leaf.Verify(x509.VerifyOptions{
+		Roots:         c.tlsConfig.RootCAs,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	hostErr := leaf.VerifyHostname(name)
+
+	daysLeft := leaf.NotAfter.Sub(now).Hours() / 24
+	r := CheckResult{
+		Up: true,
+		Values: map[string]float64{
+			"days_until_expiry": daysLeft,
+			"chain_valid":       boolValue(chainErr == nil),
+			"hostname_match":    boolValue(hostErr == nil),
+		},
+	}
+
+	switch {
+	case now.After(leaf.NotAfter):
+		r.Up = false
+		r.Err = fmt.Errorf("certificate expired %v", leaf.NotAfter)
+	case now.Before(leaf.NotBefore):
+		r.Up = false
+		r.Err = fmt.Errorf("certificate not valid before %v", leaf.NotBefore)
+	case chainErr != nil && !c.HTTPOpts.SkipTLSVerify:
+		r.Up = false
+		r.Err = chainErr
+	case hostErr != nil && !c.HTTPOpts.SkipTLSVerify:
+		r.Up = false
+		r.Err = hostErr
+	case daysLeft < float64(c.WarnDays):
+		r.degrade(fmt.Sprintf("certificate expires in %.1f days", daysLeft))
+	}
+	return r
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,375 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TrilliumIT/updog/utils"
+)
+
+//HTTPTransaction represents a check type of an ordered list of http requests sharing cookies
+const HTTPTransaction = "http_transaction"
+
+func init() {
+	RegisterChecker(HTTPTransaction, newHTTPTransactionChecker)
+}
+
+//transactionVar is a reference to an extracted value, such as {{token}}
+var transactionVar = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+//expandVars replaces the references in s with the extracted values
+func expandVars(s string, vars map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return transactionVar.ReplaceAllStringFunc(s, func(ref string) string {
+		return vars[transactionVar.FindStringSubmatch(ref)[1]]
+	})
+}
+
+//extraction saves a value from a response for later steps, from one of a json selector,
+//a regex, which saves its first group if it has one, or a header
+type extraction struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"json_path,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	Header   string `json:"header,omitempty"`
+	path     []interface{}
+	regex    *regexp.Regexp
+}
+
+func (e *extraction) compile() (err error) {
+	if e.Name == "" {
+		return errors.New("extract requires a name")
+	}
+	n := 0
+	if e.JSONPath != "" {
+		n++
+		e.path, err = parseSelector(e.JSONPath)
+		if err != nil {
+			return err
+		}
+	}
+	if e.Regex != "" {
+		n++
+		e.regex, err = regexp.Compile(e.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	if e.Header != "" {
+		n++
+	}
+	if n != 1 {
+		return fmt.Errorf("extract %v requires one of json_path, regex or header", e.Name)
+	}
+	return nil
+}
+
+func (e *extraction) extract(resp *http.Response, body []byte) (string, error) {
+	switch {
+	case e.Header != "":
+		v := resp.Header.Get(e.Header)
+		if v == "" {
+			return "", fmt.Errorf("header %v missing", e.Header)
+		}
+		return v, nil
+	case e.regex != nil:
+		m := e.regex.FindSubmatch(body)
+		if m == nil {
+			return "", fmt.Errorf("body does not match %q", e.Regex)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	}
+	var doc interface{}
+	err := json.Unmarshal(body, &doc)
+	if err != nil {
+		return "", fmt.Errorf("invalid json: %v", err)
+	}
+	v, err := selectNode(doc, e.path)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("%v is null", e.JSONPath)
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+//transactionStep is a single request of the transaction. The path may be a full url,
+//and it, the host, headers and body may reference values extracted by earlier steps.
+type transactionStep struct {
+	Name     string        `json:"name,omitempty"`
+	Path     string        `json:"path"`
+	HTTPOpts *HTTPOpts     `json:"http_options"`
+	Extract  []*extraction `json:"extract,omitempty"`
+	//opts are the http options of the step, with those it inherits from the transaction
+	opts *HTTPOpts
+}
+
+//inherit returns the http options of the step, with the headers, host and credentials it
+//does not set itself taken from the transaction
+func (s *transactionStep) inherit(t *HTTPOpts) *HTTPOpts {
+	opts := *s.HTTPOpts
+	if len(t.Headers) > 0 {
+		opts.Headers = make(httpHeaders, len(t.Headers)+len(s.HTTPOpts.Headers))
+		for k, v := range t.Headers {
+			opts.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+		for k, v := range s.HTTPOpts.Headers {
+			opts.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	if opts.Host == "" {
+		opts.Host = t.Host
+	}
+	if opts.BasicAuth == nil && opts.BearerTokenFile == "" {
+		opts.BasicAuth = t.BasicAuth
+		opts.BearerTokenFile = t.BearerTokenFile
+	}
+	return &opts
+}
+
+//clientOptions returns the names of the options which are set, which configure the client
+//shared by every step, rather than a single request
+func clientOptions(opts *HTTPOpts) []string {
+	var set []string
+	if opts.SkipTLSVerify {
+		set = append(set, "skip_tls_verify")
+	}
+	if opts.CA != "" {
+		set = append(set, "ca")
+	}
+	if opts.ClientCert != "" {
+		set = append(set, "client_cert")
+	}
+	if opts.ClientKey != "" {
+		set = append(set, "client_key")
+	}
+	if opts.FollowRedirects {
+		set = append(set, "follow_redirects")
+	}
+	if opts.ConnectionReuse != nil {
+		set = append(set, "connection_reuse")
+	}
+	return set
+}
+
+//stepOptions returns the names of the options which are set, which only apply to the request
+//and response of a single step
+func stepOptions(opts *HTTPOpts) []string {
+	var set []string
+	if opts.HTTPMethod != "" {
+		set = append(set, "http_method")
+	}
+	if opts.Body != "" {
+		set = append(set, "body")
+	}
+	if opts.ExpectBodyContains != "" {
+		set = append(set, "expect_body_contains")
+	}
+	if opts.ExpectBodyRegex != "" {
+		set = append(set, "expect_body_regex")
+	}
+	if opts.MaxBodySize != 0 {
+		set = append(set, "max_body_size")
+	}
+	if len(opts.ExpectHeaders) > 0 {
+		set = append(set, "expect_headers")
+	}
+	if len(opts.ExpectStatus) > 0 {
+		set = append(set, "expected_status")
+	}
+	return set
+}
+
+//refs returns the names of the values referenced by the step
+func (s *transactionStep) refs() []string {
+	strs := []string{s.Path, s.opts.Host, string(s.opts.Body)}
+	for _, v := range s.opts.Headers {
+		strs = append(strs, v)
+	}
+	var refs []string
+	for _, str := range strs {
+		for _, m := range transactionVar.FindAllStringSubmatch(str, -1) {
+			refs = append(refs, m[1])
+		}
+	}
+	return refs
+}
+
+func (s *transactionStep) readsBody() bool {
+	if s.opts.assertsBody() {
+		return true
+	}
+	for _, e := range s.Extract {
+		if e.Header == "" {
+			return true
+		}
+	}
+	return false
+}
+
+//run sends the request of the step, checks the response, and saves the extracted values into vars
+func (s *transactionStep) run(ctx context.Context, client *http.Client, address string, vars map[string]string) (int, time.Duration, error) {
+	opts := *s.opts
+	opts.Host = expandVars(opts.Host, vars)
+	opts.Body = Secret(expandVars(string(opts.Body), vars))
+	if len(s.opts.Headers) > 0 {
+		opts.Headers = make(httpHeaders, len(s.opts.Headers))
+		for k, v := range s.opts.Headers {
+			opts.Headers[k] = expandVars(v, vars)
+		}
+	}
+	url := expandVars(s.Path, vars)
+	if !strings.Contains(url, "://") {
+		url = strings.TrimSuffix(address, "/") + url
+	}
+
+	start := time.Now()
+	req, err := opts.newRequest(ctx, url)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	defer utils.DiscardCloseBody(resp.Body)
+	err = opts.checkStatus(resp)
+	if err == nil {
+		err = opts.checkHeaders(resp)
+	}
+	var body []byte
+	if err == nil && s.readsBody() {
+		body, err = opts.readBody(resp)
+		if err == nil {
+			err = opts.checkBody(body)
+		}
+	}
+	for _, e := range s.Extract {
+		if err != nil {
+			break
+		}
+		var v string
+		v, err = e.extract(resp, body)
+		if err != nil {
+			err = fmt.Errorf("extract %v: %v", e.Name, err)
+		}
+		vars[e.Name] = v
+	}
+	return resp.StatusCode, time.Since(start), err
+}
+
+type httpTransactionChecker struct {
+	Steps    []*transactionStep `json:"steps"`
+	HTTPOpts *HTTPOpts          `json:"http_options"`
+	client   *http.Client
+}
+
+func newHTTPTransactionChecker(options json.RawMessage) (Checker, error) {
+	c := &httpTransactionChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Steps) == 0 {
+		return nil, errors.New("http_transaction check requires steps")
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if set := stepOptions(c.HTTPOpts); len(set) > 0 {
+		return nil, fmt.Errorf("http_options %v must be set on the steps", strings.Join(set, ", "))
+	}
+	err = c.HTTPOpts.compile()
+	if err != nil {
+		return nil, err
+	}
+	// the tls, redirect and connection options apply to every step,
+	// the headers, host and credentials are the defaults of each step
+	c.client = newHTTPClient(c.HTTPOpts)
+
+	names := make(map[string]bool)
+	extracted := make(map[string]bool)
+	for n, s := range c.Steps {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("step%d", n+1)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("duplicate step name %v", s.Name)
+		}
+		names[s.Name] = true
+		if s.HTTPOpts == nil {
+			s.HTTPOpts = &HTTPOpts{}
+		}
+		if set := clientOptions(s.HTTPOpts); len(set) > 0 {
+			return nil, fmt.Errorf("step %v: http_options %v must be set for the whole transaction", s.Name, strings.Join(set, ", "))
+		}
+		if s.HTTPOpts.HTTPMethod == "" {
+			s.HTTPOpts.HTTPMethod = "GET"
+		}
+		s.opts = s.inherit(c.HTTPOpts)
+		err = s.opts.compile()
+		if err != nil {
+			return nil, fmt.Errorf("step %v: %v", s.Name, err)
+		}
+		for _, ref := range s.refs() {
+			if !extracted[ref] {
+				return nil, fmt.Errorf("step %v uses {{%v}} before it is extracted", s.Name, ref)
+			}
+		}
+		for _, e := range s.Extract {
+			err = e.compile()
+			if err != nil {
+				return nil, fmt.Errorf("step %v: %v", s.Name, err)
+			}
+			extracted[e.Name] = true
+		}
+	}
+	return c, nil
+}
+
+func (c *httpTransactionChecker) Check(ctx context.Context, address string) CheckResult {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	client := *c.client
+	client.Jar = jar
+
+	vars := make(map[string]string)
+	r := CheckResult{Up: true, Values: make(map[string]float64)}
+	var status []string
+	for _, s := range c.Steps {
+		code, rt, err := s.run(ctx, &client, address, vars)
+		ms := float64(rt) / float64(time.Millisecond)
+		r.Values[s.Name+"_ms"] = ms
+		if code != 0 {
+			r.Values[s.Name+"_status_code"] = float64(code)
+			r.StatusCode = code
+		}
+		status = append(status, fmt.Sprintf("%v=%v %.1fms", s.Name, code, ms))
+		if err != nil {
+			r.Up = false
+			r.Err = &classError{classifyError(err), fmt.Errorf("step %v: %v", s.Name, err)}
+			break
+		}
+	}
+	r.Status = strings.Join(status, " ")
+	return r
+}
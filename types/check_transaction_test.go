@@ -0,0 +1,108 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTransactionCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		switch r.URL.Path {
+		case "/login":
+			if r.Method != "POST" || user != "updog" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+			fmt.Fprint(w, `{"token": "t1"}`)
+		case "/api":
+			if c, err := r.Cookie("session"); err != nil || c.Value != "s1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, "host=%v accept=%v agent=%v token=%v user=%v",
+				r.Host, r.Header.Get("Accept"), r.Header.Get("User-Agent"), r.Header.Get("X-Token"), user)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		options string
+		up      bool
+	}{
+		{
+			"inherited",
+			`{"http_options": {"basic_auth": {"username": "updog", "password": "secret"}, "host": "updog.test",
+				"headers": {"accept": "application/json", "User-Agent": "updog"}},
+			"steps": [
+				{"path": "/login", "http_options": {"http_method": "POST"}, "extract": [{"name": "token", "json_path": "token"}]},
+				{"path": "/api", "http_options": {"headers": {"X-Token": "{{token}}"},
+					"expect_body_contains": "host=updog.test accept=application/json agent=updog token=t1 user=updog"}}
+			]}`,
+			true,
+		},
+		{
+			"overridden",
+			`{"http_options": {"basic_auth": {"username": "updog", "password": "secret"}, "host": "updog.test",
+				"headers": {"Accept": "application/json", "User-Agent": "updog"}},
+			"steps": [
+				{"path": "/login", "http_options": {"http_method": "POST", "host": "other.test"}},
+				{"path": "/api", "http_options": {"host": "other.test", "headers": {"accept": "text/plain"},
+					"basic_auth": {"username": "other"},
+					"expect_body_contains": "host=other.test accept=text/plain agent=updog token= user=other"}}
+			]}`,
+			true,
+		},
+		{
+			"without credentials",
+			`{"steps": [{"path": "/login", "http_options": {"http_method": "POST"}}]}`,
+			false,
+		},
+		{
+			"without cookie",
+			`{"steps": [{"path": "/api"}]}`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newHTTPTransactionChecker(json.RawMessage(tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := c.Check(context.Background(), srv.URL)
+			if r.Up != tt.up {
+				t.Errorf("up is %v, expected %v: %v %v", r.Up, tt.up, r.Status, r.Err)
+			}
+		})
+	}
+}
+
+func TestNewHTTPTransactionCheckerInvalid(t *testing.T) {
+	tests := []struct {
+		options string
+		err     string
+	}{
+		{`{}`, "requires steps"},
+		{`{"http_options": {"http_method": "POST"}, "steps": [{"path": "/"}]}`, "http_options http_method must be set on the steps"},
+		{`{"http_options": {"body": "x", "expected_status": [200]}, "steps": [{"path": "/"}]}`, "http_options body, expected_status must be set on the steps"},
+		{`{"steps": [{"path": "/", "http_options": {"skip_tls_verify": true}}]}`, "step step1: http_options skip_tls_verify must be set for the whole transaction"},
+		{`{"steps": [{"name": "login", "path": "/", "http_options": {"ca": "ca.pem", "client_cert": "c.pem"}}]}`, "step login: http_options ca, client_cert must be set for the whole transaction"},
+		{`{"http_options": {"bearer_token_file": "token", "basic_auth": {"username": "updog"}}, "steps": [{"path": "/"}]}`, "exclusive"},
+		{`{"steps": [{"path": "/{{token}}"}]}`, "before it is extracted"},
+		{`{"steps": [{"path": "/"}, {"name": "step1", "path": "/"}]}`, "duplicate step name"},
+	}
+	for _, tt := range tests {
+		_, err := newHTTPTransactionChecker(json.RawMessage(tt.options))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("error for %v is %v, expected %v", tt.options, err, tt.err)
+		}
+	}
+}
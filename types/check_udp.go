@@ -0,0 +1,103 @@
+package types
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+//UDP represents a check type of sending a udp datagram and matching the reply
+const UDP = "udp"
+
+const maxDatagramSize = 65535
+
+func init() {
+	RegisterChecker(UDP, newUDPChecker)
+}
+
+type udpChecker struct {
+	Send    string `json:"send,omitempty"`
+	SendHex string `json:"send_hex,omitempty"`
+	expectOpts
+	payload []byte
+}
+
+func newUDPChecker(options json.RawMessage) (Checker, error) {
+	c := &udpChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.Send != "" && c.SendHex != "":
+		return nil, errors.New("udp check takes only one of send and send_hex")
+	case c.SendHex != "":
+		c.payload, err = hex.DecodeString(c.SendHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid send_hex: %v", err)
+		}
+	case c.Send != "":
+		c.payload = []byte(c.Send)
+	default:
+		return nil, errors.New("udp check requires send or send_hex")
+	}
+	err = c.compile()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *udpChecker) Check(ctx context.Context, address string) CheckResult {
+	conn, err := dialContext(ctx, "udp", address)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer closeConn(conn)
+	if dl, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(dl)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+	}
+
+	_, err = conn.Write(c.payload)
+	if err != nil {
+		return CheckResult{Err: udpError(err)}
+	}
+
+	// any datagram that matches is a success, keep reading others until the deadline
+	buf := make([]byte, maxDatagramSize)
+	var last []byte
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if last != nil {
+				return CheckResult{Err: fmt.Errorf("reply did not match: %q", last)}
+			}
+			return CheckResult{Err: udpError(err)}
+		}
+		if c.matches(buf[:n]) {
+			return CheckResult{Up: true}
+		}
+		last = append(last[:0], buf[:n]...)
+	}
+}
+
+//udpError distinguishes an icmp port unreachable from a timeout waiting for a reply
+func udpError(err error) error {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return &classError{ErrorClassTimeout, errors.New("timeout waiting for reply")}
+	}
+	if oe, ok := err.(*net.OpError); ok {
+		if se, ok := oe.Err.(*os.SyscallError); ok && se.Err == syscall.ECONNREFUSED {
+			return &classError{ErrorClassRefused, errors.New("port unreachable")}
+		}
+	}
+	return err
+}
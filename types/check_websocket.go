@@ -0,0 +1,151 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+//WebSocket represents a check type of upgrading to a websocket, and matching a message
+const WebSocket = "websocket"
+
+func init() {
+	RegisterChecker(WebSocket, newWebSocketChecker)
+}
+
+type webSocketChecker struct {
+	Path string `json:"path,omitempty"`
+	Send string `json:"send,omitempty"`
+	expectOpts
+	HTTPOpts *HTTPOpts `json:"http_options"`
+	dialer   *websocket.Dialer
+}
+
+func newWebSocketChecker(options json.RawMessage) (Checker, error) {
+	c := &webSocketChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.HTTPOpts == nil {
+		c.HTTPOpts = &HTTPOpts{}
+	}
+	if c.HTTPOpts.HTTPMethod == "" {
+		c.HTTPOpts.HTTPMethod = "GET"
+	}
+	err = c.HTTPOpts.compile()
+	if err != nil {
+		return nil, err
+	}
+	err = c.compile()
+	if err != nil {
+		return nil, err
+	}
+	c.dialer = &websocket.Dialer{
+		TLSClientConfig: newTLSConfig(c.HTTPOpts),
+	}
+	return c, nil
+}
+
+//webSocketURL returns the websocket url of the address, which may be an http url
+func webSocketURL(address, path string) string {
+	switch {
+	case strings.HasPrefix(address, "http://"):
+		address = "ws://" + strings.TrimPrefix(address, "http://")
+	case strings.HasPrefix(address, "https://"):
+		address = "wss://" + strings.TrimPrefix(address, "https://")
+	case !strings.Contains(address, "://"):
+		address = "ws://" + address
+	}
+	return strings.TrimSuffix(address, "/") + path
+}
+
+func (c *webSocketChecker) Check(ctx context.Context, address string) CheckResult {
+	url := webSocketURL(address, c.Path)
+	// the request carries the headers and credentials of the http options
+	req, err := c.HTTPOpts.newRequest(ctx, url)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		req.Header.Set("Host", req.Host)
+	}
+
+	d := *c.dialer
+	d.NetDial = func(network, addr string) (net.Conn, error) {
+		return dialContext(ctx, network, addr)
+	}
+	conn, resp, err := d.DialContext(ctx, url, req.Header)
+	if err != nil {
+		if resp != nil {
+			return CheckResult{
+				Err:        &classError{ErrorClassHandshake, fmt.Errorf("websocket handshake failed with status %v", resp.StatusCode)},
+				StatusCode: resp.StatusCode,
+			}
+		}
+		if err == websocket.ErrBadHandshake {
+			return CheckResult{Err: &classError{ErrorClassHandshake, err}}
+		}
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		err := conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		if err != nil {
+			log.WithError(err).Debug("Error sending websocket close")
+		}
+		closeConn(conn)
+	}()
+	r := CheckResult{StatusCode: resp.StatusCode}
+
+	if dl, ok := ctx.Deadline(); ok {
+		err = conn.SetWriteDeadline(dl)
+		if err == nil {
+			err = conn.SetReadDeadline(dl)
+		}
+		if err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	if c.Send != "" {
+		err = conn.WriteMessage(websocket.TextMessage, []byte(c.Send))
+		if err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	if !c.expects() {
+		r.Up = true
+		return r
+	}
+
+	// any message that matches is a success, keep reading others until the deadline
+	var last []byte
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				err = errors.New("timeout waiting for message")
+				if last != nil {
+					err = fmt.Errorf("timeout waiting for message, last was %q", last)
+				}
+				r.Err = &classError{ErrorClassTimeout, err}
+				return r
+			}
+			r.Err = err
+			return r
+		}
+		if c.matches(msg) {
+			r.Up = true
+			return r
+		}
+		last = msg
+	}
+}
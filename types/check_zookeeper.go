@@ -0,0 +1,153 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+//ZooKeeper represents a check type of zookeeper four letter words
+const ZooKeeper = "zookeeper"
+
+const zkNotServing = "not currently serving requests"
+
+func init() {
+	RegisterChecker(ZooKeeper, newZooKeeperChecker)
+}
+
+type zooKeeperChecker struct {
+	Command  string `json:"command"`
+	SkipRuok bool   `json:"skip_ruok,omitempty"`
+}
+
+func newZooKeeperChecker(options json.RawMessage) (Checker, error) {
+	c := &zooKeeperChecker{}
+	err := json.Unmarshal(options, c)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Command {
+	case "":
+		c.Command = "srvr"
+	case "srvr", "mntr":
+	default:
+		return nil, fmt.Errorf("unsupported zookeeper command %q", c.Command)
+	}
+	return c, nil
+}
+
+func (c *zooKeeperChecker) Check(ctx context.Context, address string) CheckResult {
+	if !c.SkipRuok {
+		out, err := zkCommand(ctx, address, "ruok")
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		if out != "imok" {
+			return CheckResult{Err: fmt.Errorf("ruok returned %q", out)}
+		}
+	}
+
+	out, err := zkCommand(ctx, address, c.Command)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if strings.Contains(out, zkNotServing) {
+		return CheckResult{Err: fmt.Errorf("%v returned %q", c.Command, out)}
+	}
+
+	var role string
+	var values map[string]float64
+	if c.Command == "mntr" {
+		role, values = parseZKMntr(out)
+	} else {
+		role, values = parseZKSrvr(out)
+	}
+	if role == "" {
+		return CheckResult{Err: fmt.Errorf("no mode in %v output", c.Command), Values: values}
+	}
+	return CheckResult{Up: true, Role: role, Status: role, Values: values}
+}
+
+//zkCommand sends a four letter word, and returns the reply. The server closes
+//the connection after replying.
+func zkCommand(ctx context.Context, address, cmd string) (string, error) {
+	conn, err := dialTCP(ctx, address, nil)
+	if err != nil {
+		return "", err
+	}
+	defer closeConn(conn)
+	_, err = conn.Write([]byte(cmd))
+	if err != nil {
+		return "", err
+	}
+	out, err := ioutil.ReadAll(io.LimitReader(conn, defaultMaxBodySize))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+//parseZKSrvr parses the mode and stats from the output of srvr, such as
+//
+//	Latency min/avg/max: 0/0/15
+//	Mode: follower
+func parseZKSrvr(out string) (string, map[string]float64) {
+	var role string
+	values := make(map[string]float64)
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		p := strings.SplitN(s.Text(), ":", 2)
+		if len(p) != 2 {
+			continue
+		}
+		k, v := strings.TrimSpace(p[0]), strings.TrimSpace(p[1])
+		switch k {
+		case "Mode":
+			role = v
+		case "Latency min/avg/max":
+			for i, l := range strings.Split(v, "/") {
+				f, err := strconv.ParseFloat(l, 64)
+				if err == nil && i < 3 {
+					values["latency_"+[]string{"min", "avg", "max"}[i]] = f
+				}
+			}
+		default:
+			f, err := strconv.ParseFloat(v, 64)
+			if err == nil {
+				values[strings.Replace(strings.ToLower(k), " ", "_", -1)] = f
+			}
+		}
+	}
+	return role, values
+}
+
+//parseZKMntr parses the tab separated output of mntr, such as
+//
+//	zk_server_state	leader
+//	zk_avg_latency	0
+func parseZKMntr(out string) (string, map[string]float64) {
+	var role string
+	values := make(map[string]float64)
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		p := strings.SplitN(s.Text(), "\t", 2)
+		if len(p) != 2 {
+			continue
+		}
+		k, v := strings.TrimSpace(p[0]), strings.TrimSpace(p[1])
+		if k == "zk_server_state" {
+			role = v
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			values[strings.TrimPrefix(k, "zk_")] = f
+		}
+	}
+	return role, values
+}
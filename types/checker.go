@@ -0,0 +1,216 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+//Checker performs a single check of an instance
+type Checker interface {
+	Check(ctx context.Context, address string) CheckResult
+}
+
+//CheckResult is the outcome of a single check of an instance
+type CheckResult struct {
+	Up bool
+	//Degraded is set when the instance is up, but should be looked at, the reason is in the status
+	Degraded bool
+	//Err is the reason the check failed
+	Err error
+	//Status is the status reported by the instance, if the check type has one,
+	//followed by the reasons it is degraded
+	Status string
+	//Role is the role of the instance in its cluster, such as leader or follower
+	Role string
+	//Redirects are the urls followed by an http check
+	Redirects []string
+	//Values are numeric observations made by the check
+	Values map[string]float64
+	//Slow is set when the response took longer than the warn_response_time
+	Slow bool
+	//StatusCode is the http status code of the response, if the check type has one
+	StatusCode int
+	//ResolvedIP is the address the check connected to
+	ResolvedIP string
+	//HTTPTiming is the time spent in each phase of an http check
+	HTTPTiming *HTTPTiming
+	//ConnectionReused is set when the check was sent on a connection kept open from a previous check
+	ConnectionReused bool
+	//Addresses are the results of each address the instance resolved to, with resolve_all
+	Addresses map[string]AddressStatus
+}
+
+//degrade marks the result degraded and adds the reason to its status. A degraded instance
+//is still up, so the reason is not an error.
+func (r *CheckResult) degrade(reason string) {
+	r.Degraded = true
+	if r.Status != "" {
+		reason = r.Status + ", " + reason
+	}
+	r.Status = reason
+}
+
+//Error classes of failed checks
+const (
+	ErrorClassDNS        = "dns"
+	ErrorClassRefused    = "refused"
+	ErrorClassTimeout    = "timeout"
+	ErrorClassTLS        = "tls"
+	ErrorClassNetwork    = "network"
+	ErrorClassHTTPStatus = "http_status"
+	ErrorClassHandshake  = "handshake"
+	ErrorClassAssertion  = "assertion"
+)
+
+//classError is an error whose class is known where it is created
+type classError struct {
+	class string
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+//classifyError returns the class of the error which failed a check, unwrapping
+//url, net and syscall errors. Errors from the checks themselves are assertions.
+func classifyError(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case *classError:
+			return e.class
+		case *net.DNSError:
+			return ErrorClassDNS
+		case *url.Error:
+			err = e.Err
+			continue
+		case *net.OpError:
+			if e.Timeout() {
+				return ErrorClassTimeout
+			}
+			err = e.Err
+			continue
+		case *os.SyscallError:
+			err = e.Err
+			continue
+		case syscall.Errno:
+			if e == syscall.ECONNREFUSED {
+				return ErrorClassRefused
+			}
+			if e.Timeout() {
+				return ErrorClassTimeout
+			}
+			return ErrorClassNetwork
+		}
+		if err == context.DeadlineExceeded {
+			return ErrorClassTimeout
+		}
+		if strings.HasPrefix(err.Error(), "tls: ") || strings.HasPrefix(err.Error(), "x509: ") {
+			return ErrorClassTLS
+		}
+		if ne, ok := err.(net.Error); ok {
+			if ne.Timeout() {
+				return ErrorClassTimeout
+			}
+			return ErrorClassNetwork
+		}
+		return ErrorClassAssertion
+	}
+	return ""
+}
+
+//connTrace records the address connected to by checks which dial with the context
+type connTrace struct {
+	lock sync.Mutex
+	addr string
+}
+
+func (t *connTrace) withContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.addr == "" {
+				t.addr = addr
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				return
+			}
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.addr = addr
+		},
+	})
+}
+
+//ip returns the ip address connected to, or the last one tried if none succeeded
+func (t *connTrace) ip() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	host, _, err := net.SplitHostPort(t.addr)
+	if err != nil {
+		return t.addr
+	}
+	return host
+}
+
+//CheckerFactory creates a Checker from the raw check_options json
+type CheckerFactory func(options json.RawMessage) (Checker, error)
+
+var (
+	checkersLock sync.RWMutex
+	checkers     = make(map[string]CheckerFactory)
+)
+
+//RegisterChecker makes a check type available to check_options by name.
+//It panics if the name is registered twice, or if the factory is nil.
+func RegisterChecker(name string, factory CheckerFactory) {
+	checkersLock.Lock()
+	defer checkersLock.Unlock()
+	if factory == nil {
+		panic("updog: RegisterChecker factory is nil")
+	}
+	if _, dup := checkers[name]; dup {
+		panic("updog: RegisterChecker called twice for " + name)
+	}
+	checkers[name] = factory
+}
+
+func newChecker(name string, options json.RawMessage) (Checker, error) {
+	checkersLock.RLock()
+	factory, ok := checkers[name]
+	checkersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown check type %q", name)
+	}
+	if len(options) == 0 {
+		options = json.RawMessage("{}")
+	}
+	return factory(options)
+}
+
+//splitAddress returns the host and port of an instance address, which may be
+//a url or a host:port pair. The defaultPort is used if none is specified.
+func splitAddress(address, defaultPort string) (host, port string) {
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err == nil {
+			address = u.Host
+		}
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return strings.Trim(address, "[]"), defaultPort
+	}
+	return host, port
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+// +build !windows
+
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExecCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		up         bool
+		degraded   bool
+		errorClass string
+		values     map[string]float64
+	}{
+		{"ok", "echo 'OK - fine | t=1ms'", true, false, "", map[string]float64{"t": 1}},
+		{"warning", "echo 'WARNING - slow'; exit 1", true, true, "", nil},
+		{"critical", "echo 'CRITICAL - down'; exit 2", false, false, ErrorClassAssertion, nil},
+		{"unknown", "echo 'UNKNOWN'; exit 3", false, false, ErrorClassAssertion, nil},
+		{"timeout", "sleep 10", false, false, ErrorClassTimeout, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, _ := json.Marshal(map[string][]string{"command": {"sh", "-c", tt.script}})
+			c, err := newExecChecker(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			r := c.Check(ctx, "localhost:80")
+			if r.Up != tt.up || r.Degraded != tt.degraded {
+				t.Errorf("up is %v and degraded %v, expected %v and %v: %v", r.Up, r.Degraded, tt.up, tt.degraded, r.Err)
+			}
+			if c := classifyError(r.Err); c != tt.errorClass {
+				t.Errorf("error class is %q, expected %q: %v", c, tt.errorClass, r.Err)
+			}
+			if tt.values != nil && r.Values["t"] != tt.values["t"] {
+				t.Errorf("values are %v, expected %v", r.Values, tt.values)
+			}
+		})
+	}
+}
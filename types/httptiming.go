@@ -0,0 +1,104 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+//HTTPTiming is the time spent in each phase of an http check. When redirects
+//are followed, the dns, connect and tls phases are the sum of each request.
+type HTTPTiming struct {
+	DNS     time.Duration `json:"dns"`
+	Connect time.Duration `json:"connect"`
+	TLS     time.Duration `json:"tls"`
+	//FirstByte is the time from the start of the check to the first byte of the last response
+	FirstByte time.Duration `json:"first_byte"`
+	Total     time.Duration `json:"total"`
+}
+
+//httpTimer traces the phases of http requests made with its context
+type httpTimer struct {
+	lock                         sync.Mutex
+	start, dnsStart, tlsStart    time.Time
+	connectStart                 map[string]time.Time
+	dns, connect, tls, firstByte time.Duration
+	gotConn, newConn             bool
+}
+
+func newHTTPTimer() *httpTimer {
+	return &httpTimer{start: time.Now(), connectStart: make(map[string]time.Time)}
+}
+
+func (t *httpTimer) withContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.dns += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.connectStart[addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			// only the successful connection counts, when several addresses are raced
+			if err == nil {
+				t.connect += time.Since(t.connectStart[addr])
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.tls += time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.gotConn = true
+			if !info.Reused {
+				t.newConn = true
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.firstByte = time.Since(t.start)
+		},
+	})
+}
+
+//reused returns true if every request, including those redirected to, was sent on a kept alive connection
+func (t *httpTimer) reused() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.gotConn && !t.newConn
+}
+
+//timing returns the phases traced so far, with the total time since the timer was created
+func (t *httpTimer) timing() *HTTPTiming {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &HTTPTiming{
+		DNS:       t.dns,
+		Connect:   t.connect,
+		TLS:       t.tls,
+		FirstByte: t.firstByte,
+		Total:     time.Since(t.start),
+	}
+}
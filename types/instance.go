@@ -1,17 +1,11 @@
 package types
 
 import (
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
-	"io/ioutil"
 	"math/rand"
-	"net"
-	"net/http"
 	"sync"
 	"time"
 
-	"github.com/TrilliumIT/updog/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -45,10 +39,28 @@ func (i *Instance) MarshalJSON() ([]byte, error) {
 
 //InstanceStatus represents the status of the instance
 type InstanceStatus struct {
-	Up           bool          `json:"up"`
-	ResponseTime time.Duration `json:"response_time"`
-	TimeStamp    time.Time     `json:"timestamp"`
-	LastChange   time.Time     `json:"last_change"`
+	Up           bool                     `json:"up"`
+	Degraded     bool                     `json:"degraded,omitempty"`
+	Slow         bool                     `json:"slow,omitempty"`
+	Values       map[string]float64       `json:"values,omitempty"`
+	Status       string                   `json:"status,omitempty"`
+	Role         string                   `json:"role,omitempty"`
+	Error        string                   `json:"error,omitempty"`
+	ErrorClass   string                   `json:"error_class,omitempty"`
+	StatusCode   int                      `json:"status_code,omitempty"`
+	ResolvedIP   string                   `json:"resolved_ip,omitempty"`
+	Redirects    []string                 `json:"redirects,omitempty"`
+	Addresses    map[string]AddressStatus `json:"addresses,omitempty"`
+	ResponseTime time.Duration            `json:"response_time"`
+	HTTPTiming   *HTTPTiming              `json:"http_timing,omitempty"`
+	ConnReused   bool                     `json:"connection_reused,omitempty"`
+	Attempts     int                      `json:"attempts"`
+	TimeStamp    time.Time                `json:"timestamp"`
+	LastChange   time.Time                `json:"last_change"`
+	//Streak is the number of consecutive results which disagree with Up,
+	//the state changes when it reaches StreakNeeded
+	Streak       int `json:"streak,omitempty"`
+	StreakNeeded int `json:"streak_needed,omitempty"`
 	idx, cidx    uint64
 }
 
@@ -103,36 +115,70 @@ func (i *Instance) StartChecks(co *CheckOptions) {
 		i.brokerLock.Unlock()
 	}
 
-	interval := time.Duration(co.Interval)
+	if co.checker == nil {
+		log.WithField("type", co.Stype).Error("No checker for service type")
+		return
+	}
+
+	interval := co.interval()
 	go func() {
 		var t *time.Ticker
-		var up, lastUp bool
+		var lastUp, lastDegraded, lastSlow bool
+		var lastRole string
+		var up, degraded, slow bool
+		var role string
+		var streak, need int
 		var idx, cidx uint64
-		var start, end time.Time
-		var client *http.Client
+		var start time.Time
+		var r CheckResult
+		var attempts int
+		var rt time.Duration
 		for {
 			idx++
 			start = time.Now()
-			switch co.Stype {
-			case TCPConnect:
-				up = tcpConnectCheck(i.address, interval)
-			case HTTPStatus:
-				if client == nil {
-					client = newHTTPClient(co.HTTPOpts, interval)
-				}
-				up = httpStatusCheck(co.HTTPOpts, i.address, client)
-			default:
-				log.WithField("type", co.Stype).Error("Unknown service type")
-				return
+			r, attempts, rt = co.check(i.address)
+			// the state only changes after rise or fall consecutive results which disagree with it
+			need = co.fall()
+			if r.Up {
+				need = co.rise()
+			}
+			streak++
+			if idx == 1 || r.Up == up {
+				streak = 0
 			}
-			end = time.Now()
-			if up != lastUp {
-				lastUp = up
+			if streak == 0 || streak >= need {
+				up, degraded, slow, role, streak = r.Up, r.Up && r.Degraded, r.Up && r.Slow, r.Role, 0
+			}
+			if streak == 0 {
+				need = 0
+			}
+			if up != lastUp || degraded != lastDegraded || slow != lastSlow || role != lastRole {
+				lastUp, lastDegraded, lastSlow, lastRole = up, degraded, slow, role
 				cidx = idx
 			}
+			var errStr string
+			if r.Err != nil {
+				errStr = r.Err.Error()
+			}
 			go func(st InstanceStatus) { i.broker.notifier <- st }(InstanceStatus{
 				Up:           up,
-				ResponseTime: end.Sub(start),
+				Degraded:     degraded,
+				Slow:         slow,
+				Values:       r.Values,
+				Status:       r.Status,
+				Role:         role,
+				Error:        errStr,
+				ErrorClass:   classifyError(r.Err),
+				StatusCode:   r.StatusCode,
+				ResolvedIP:   r.ResolvedIP,
+				Redirects:    r.Redirects,
+				Addresses:    r.Addresses,
+				ResponseTime: rt,
+				HTTPTiming:   r.HTTPTiming,
+				ConnReused:   r.ConnectionReused,
+				Attempts:     attempts,
+				Streak:       streak,
+				StreakNeeded: need,
 				TimeStamp:    start,
 				idx:          idx,
 				cidx:         cidx,
@@ -148,72 +194,3 @@ func (i *Instance) StartChecks(co *CheckOptions) {
 		}
 	}()
 }
-
-func tcpConnectCheck(address string, timeout time.Duration) bool {
-	conn, err := net.DialTimeout("tcp", address, timeout)
-	if err == nil {
-		defer func() {
-			err = conn.Close()
-			if err != nil {
-				log.WithError(err).Error("Error closing connection")
-			}
-		}()
-	}
-	return err == nil
-}
-
-func newHTTPClient(opts *HTTPOpts, timeout time.Duration) *http.Client {
-	l := log.WithFields(log.Fields{"method": opts.HTTPMethod, "timeout": timeout, "skip_tls_verify": opts})
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: opts.SkipTLSVerify,
-	}
-
-	if opts.CA != "" {
-		rootCert := x509.NewCertPool()
-		data, err := ioutil.ReadFile(opts.CA)
-		if err != nil {
-			l.WithError(err).WithField("file", opts.CA).Error("Error reading CA file")
-		}
-		ok := rootCert.AppendCertsFromPEM(data)
-		if !ok {
-			l.Error("Error adding ca to root store")
-		}
-		tlsConfig.RootCAs = rootCert
-	}
-
-	if (opts.ClientCert != "") || (opts.ClientKey != "") {
-		clientCert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
-		if err != nil {
-			l.WithField("certificate", opts.ClientCert).WithField("key", opts.ClientKey).WithError(err).Error("Unable to load client tls key")
-		}
-		tlsConfig.Certificates = append(tlsConfig.Certificates, clientCert)
-	}
-
-	client := &http.Client{
-		Timeout: timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-		Transport: &http.Transport{
-			TLSClientConfig: tlsConfig,
-		},
-	}
-
-	return client
-}
-
-func httpStatusCheck(opts *HTTPOpts, address string, client *http.Client) bool {
-	l := log.WithFields(log.Fields{"method": opts.HTTPMethod, "address": address, "skip_tls_verify": opts})
-	req, err := http.NewRequest(opts.HTTPMethod, address, nil)
-	if err != nil {
-		l.WithError(err).Error("Failed to create http request.")
-	}
-	resp, err := client.Do(req)
-	if err == nil {
-		defer utils.DiscardCloseBody(resp.Body)
-	}
-	if err != nil {
-		l.WithError(err).Error("Error doing http request")
-	}
-	return err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 399
-}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+//AddressStatus is the result of checking one of the addresses an instance resolves to
+type AddressStatus struct {
+	Up           bool          `json:"up"`
+	Degraded     bool          `json:"degraded,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	ErrorClass   string        `json:"error_class,omitempty"`
+	StatusCode   int           `json:"status_code,omitempty"`
+	ResponseTime time.Duration `json:"response_time"`
+	Attempts     int           `json:"attempts"`
+}
+
+type resolvedKey struct{}
+
+//resolvedAddr is the ip to connect to in place of the host it was resolved from
+type resolvedAddr struct {
+	host, ip string
+}
+
+func withResolvedIP(ctx context.Context, host, ip string) context.Context {
+	return context.WithValue(ctx, resolvedKey{}, resolvedAddr{host: host, ip: ip})
+}
+
+func resolvedIP(ctx context.Context) (resolvedAddr, bool) {
+	ra, ok := ctx.Value(resolvedKey{}).(resolvedAddr)
+	return ra, ok
+}
+
+//dialContext dials the address, connecting to the resolved ip in the context in place of its host.
+//Other hosts, such as those redirected to, are dialed as they are.
+func dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if ra, ok := resolvedIP(ctx); ok {
+		if host, port, err := net.SplitHostPort(address); err == nil && strings.EqualFold(host, ra.host) {
+			address = net.JoinHostPort(ra.ip, port)
+		}
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+//serverName returns the name to verify the tls certificate of the address against. When each
+//resolved address is checked, it is the host name the address was resolved from.
+func serverName(ctx context.Context, address string) string {
+	if ra, ok := resolvedIP(ctx); ok {
+		return ra.host
+	}
+	host, _ := splitAddress(address, "")
+	return host
+}
+
+//replaceHost returns the address with its host replaced by the ip. Urls are not changed,
+//http checks connect to the ip with dialContext, and keep the host name for the request.
+func replaceHost(address, ip string) string {
+	if strings.Contains(address, "://") {
+		return address
+	}
+	if _, port, err := net.SplitHostPort(address); err == nil {
+		return net.JoinHostPort(ip, port)
+	}
+	return ip
+}
+
+//checkAll resolves the host of the address, and checks each of its addresses. The instance is up
+//if any of the addresses are, and degraded if any of them are down or degraded.
+func (co *CheckOptions) checkAll(ctx context.Context, address string) (CheckResult, int, time.Duration) {
+	host, _ := splitAddress(address, "")
+	if net.ParseIP(host) != nil {
+		return co.checkRetry(ctx, address)
+	}
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return CheckResult{Err: err}, 1, time.Since(start)
+	}
+
+	type addressResult struct {
+		ip       string
+		r        CheckResult
+		attempts int
+		rt       time.Duration
+	}
+	results := make(chan addressResult)
+	for _, a := range addrs {
+		go func(ip string) {
+			r, attempts, rt := co.checkRetry(withResolvedIP(ctx, host, ip), replaceHost(address, ip))
+			results <- addressResult{ip: ip, r: r, attempts: attempts, rt: rt}
+		}(a.IP.String())
+	}
+	byIP := make(map[string]addressResult, len(addrs))
+	var ips []string
+	for range addrs {
+		ar := <-results
+		byIP[ar.ip] = ar
+		ips = append(ips, ar.ip)
+	}
+	sort.Strings(ips)
+
+	var r CheckResult
+	var degraded bool
+	var attempts int
+	var rt time.Duration
+	var errs []string
+	var firstErr error
+	addresses := make(map[string]AddressStatus, len(ips))
+	for _, ip := range ips {
+		ar := byIP[ip]
+		as := AddressStatus{
+			Up:           ar.r.Up,
+			Degraded:     ar.r.Up && ar.r.Degraded,
+			ErrorClass:   classifyError(ar.r.Err),
+			StatusCode:   ar.r.StatusCode,
+			ResponseTime: ar.rt,
+			Attempts:     ar.attempts,
+		}
+		if ar.r.Err != nil {
+			as.Error = ar.r.Err.Error()
+			errs = append(errs, fmt.Sprintf("%v: %v", ip, ar.r.Err))
+			if firstErr == nil {
+				firstErr = ar.r.Err
+			}
+		}
+		addresses[ip] = as
+		// the values and status of the first address which is up represent the instance
+		if ar.r.Up && !r.Up || len(addresses) == 1 {
+			r = ar.r
+		}
+		if !ar.r.Up || ar.r.Degraded {
+			degraded = true
+		}
+		if ar.attempts > attempts {
+			attempts = ar.attempts
+		}
+		if ar.rt > rt {
+			rt = ar.rt
+		}
+	}
+	r.Degraded = degraded
+	r.Addresses = addresses
+	r.ResolvedIP = strings.Join(ips, ",")
+	r.Err = nil
+	if firstErr != nil {
+		r.Err = &classError{classifyError(firstErr), errors.New(strings.Join(errs, "; "))}
+	}
+	return r, attempts, rt
+}
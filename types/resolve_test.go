@@ -0,0 +1,65 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDialContextResolved(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx = withResolvedIP(ctx, "updog.invalid", "127.0.0.1")
+	conn, err := dialContext(ctx, "tcp", net.JoinHostPort("UPDOG.invalid", port))
+	if err != nil {
+		t.Fatalf("resolved host not dialed at its ip: %v", err)
+	}
+	_ = conn.Close()
+	conn, err = dialContext(ctx, "tcp", net.JoinHostPort("otherhost.invalid", port))
+	if err == nil {
+		_ = conn.Close()
+		t.Error("other host dialed at the resolved ip")
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			_, port, _ := net.SplitHostPort(r.Host)
+			http.Redirect(w, r, "http://otherhost.invalid:"+port+"/", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	address := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	var co CheckOptions
+	err := json.Unmarshal([]byte(`{"type": "http_status", "resolve_all": true, "timeout": "2s",
+		"http_options": {"follow_redirects": true}}`), &co)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, _ := co.check(address + "/")
+	if !r.Up {
+		t.Errorf("down: %v", r.Err)
+	}
+	if as, ok := r.Addresses["127.0.0.1"]; !ok || !as.Up {
+		t.Errorf("127.0.0.1 not up in %+v", r.Addresses)
+	}
+
+	r, _, _ = co.check(address + "/redirect")
+	if r.Up {
+		t.Errorf("redirect to another host checked at the resolved ip: %+v", r.Addresses)
+	}
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+//Secret is a string in the config, such as a password, which is redacted when marshalled
+type Secret string
+
+//MarshalJSON hides the secret, so it is not shown in the config api
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return json.Marshal("")
+	}
+	return json.Marshal(redacted)
+}
+
+//fileSecret is a secret read from a file, which is read again when the file changes
+type fileSecret struct {
+	path    string
+	lock    sync.Mutex
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+func newFileSecret(path string) *fileSecret {
+	return &fileSecret{path: path}
+}
+
+//get returns the contents of the file, without surrounding whitespace
+func (f *fileSecret) get() (string, error) {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.modTime.IsZero() && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.value, nil
+	}
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	f.value = strings.TrimSpace(string(b))
+	f.modTime, f.size = fi.ModTime(), fi.Size()
+	return f.value, nil
+}
+
+//sensitiveHeaders are substrings of header names whose values are redacted
+var sensitiveHeaders = []string{"auth", "token", "secret", "key", "cookie", "password"}
+
+//httpHeaders are request headers, whose credential values are redacted when marshalled
+type httpHeaders map[string]string
+
+//MarshalJSON hides the values of headers which look like they carry credentials
+func (h httpHeaders) MarshalJSON() ([]byte, error) {
+	m := make(map[string]string, len(h))
+	for k, v := range h {
+		m[k] = v
+		lk := strings.ToLower(k)
+		for _, s := range sensitiveHeaders {
+			if strings.Contains(lk, s) {
+				m[k] = redacted
+				break
+			}
+		}
+	}
+	return json.Marshal(m)
+}
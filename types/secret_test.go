@@ -0,0 +1,104 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPOptsRedacted(t *testing.T) {
+	var co CheckOptions
+	err := json.Unmarshal([]byte(`{
+		"type": "http_status",
+		"http_options": {
+			"http_method": "POST",
+			"headers": {"X-Api-Key": "key-secret", "Accept": "application/json"},
+			"basic_auth": {"username": "updog", "password": "password-secret"},
+			"body": "{\"password\": \"body-secret\"}"
+		}
+	}`), &co)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(&co)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"key-secret", "password-secret", "body-secret"} {
+		if strings.Contains(string(b), s) {
+			t.Errorf("%v shown in %s", s, b)
+		}
+	}
+	var shown struct {
+		HTTPOpts HTTPOpts `json:"http_options"`
+	}
+	err = json.Unmarshal(b, &shown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := shown.HTTPOpts
+	if opts.Headers["Accept"] != "application/json" || opts.Headers["X-Api-Key"] != redacted {
+		t.Errorf("headers shown as %v", opts.Headers)
+	}
+	if opts.BasicAuth.Username != "updog" || opts.BasicAuth.Password != redacted {
+		t.Errorf("basic_auth shown as %+v", opts.BasicAuth)
+	}
+	if opts.Body != redacted {
+		t.Errorf("body shown as %v", opts.Body)
+	}
+
+	req, err := co.checker.(*httpStatusChecker).HTTPOpts.newRequest(context.Background(), "http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"password": "body-secret"}` {
+		t.Errorf("request body is %s", body)
+	}
+}
+
+func TestFileSecret(t *testing.T) {
+	f, err := ioutil.TempFile("", "updog-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	err = ioutil.WriteFile(f.Name(), []byte("first\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := newFileSecret(f.Name())
+	v, err := fs.get()
+	if err != nil || v != "first" {
+		t.Fatalf("got %q, %v", v, err)
+	}
+
+	err = ioutil.WriteFile(f.Name(), []byte("second value\n"), 0600)
+	if err == nil {
+		// the modification time may not have changed, the size has
+		err = os.Chtimes(f.Name(), time.Now(), time.Now().Add(time.Second))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = fs.get()
+	if err != nil || v != "second value" {
+		t.Errorf("got %q, %v after the file changed", v, err)
+	}
+
+	err = os.Remove(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fs.get()
+	if err == nil {
+		t.Error("expected error reading a removed file")
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -8,37 +11,204 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const (
-	maxServiceDepth = 1
-	//HTTPStatus represents a check type of http status
-	HTTPStatus = "http_status"
-	//TCPConnect represents a check type of tcp syn/ack
-	TCPConnect = "tcp_connect"
-)
+const maxServiceDepth = 1
+
+const defaultInterval = 10 * time.Second
 
 //CheckOptions represents the options for instance checks
 type CheckOptions struct {
-	Stype      string    `json:"type"`
-	HTTPMethod string    `json:"http_method"`
-	Interval   Interval  `json:"interval"`
-	HTTPOpts   *HTTPOpts `json:"http_options"`
+	Stype    string   `json:"type"`
+	Interval Interval `json:"interval"`
+	//Timeout is the limit of a single attempt, it defaults to the interval
+	Timeout Interval `json:"timeout,omitempty"`
+	//Retries are the attempts made after a failure, before the instance is reported down
+	Retries    int      `json:"retries,omitempty"`
+	RetryDelay Interval `json:"retry_delay,omitempty"`
+	//Rise and Fall are the consecutive results needed to change the instance to up, or down
+	Rise int `json:"rise,omitempty"`
+	Fall int `json:"fall,omitempty"`
+	//WarnResponseTime marks an instance slow and degraded, MaxResponseTime marks it down
+	WarnResponseTime Interval `json:"warn_response_time,omitempty"`
+	MaxResponseTime  Interval `json:"max_response_time,omitempty"`
+	//ResolveAll checks each of the addresses the instance host name resolves to
+	ResolveAll bool `json:"resolve_all,omitempty"`
+	raw        json.RawMessage
+	checker    Checker
+}
+
+//UnmarshalJSON unmarshals the JSON bytes, and creates the checker for the check type
+func (co *CheckOptions) UnmarshalJSON(data []byte) (err error) {
+	type checkOptions CheckOptions
+	err = json.Unmarshal(data, (*checkOptions)(co))
+	if err != nil {
+		return err
+	}
+	if co.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %v", time.Duration(co.Timeout))
+	}
+	if co.Retries < 0 {
+		return fmt.Errorf("invalid retries: %v", co.Retries)
+	}
+	if co.RetryDelay < 0 {
+		return fmt.Errorf("invalid retry_delay: %v", time.Duration(co.RetryDelay))
+	}
+	if co.Rise < 0 {
+		return fmt.Errorf("invalid rise: %v", co.Rise)
+	}
+	if co.Fall < 0 {
+		return fmt.Errorf("invalid fall: %v", co.Fall)
+	}
+	if co.WarnResponseTime < 0 {
+		return fmt.Errorf("invalid warn_response_time: %v", time.Duration(co.WarnResponseTime))
+	}
+	if co.MaxResponseTime < 0 {
+		return fmt.Errorf("invalid max_response_time: %v", time.Duration(co.MaxResponseTime))
+	}
+	co.raw = append(json.RawMessage{}, data...)
+	if co.Stype == "" {
+		return nil
+	}
+	co.checker, err = newChecker(co.Stype, co.raw)
+	return err
 }
 
-//HTTPOpts are the http client options for checking the instances of this service
-type HTTPOpts struct {
-	HTTPMethod    string `json:"http_method"`
-	SkipTLSVerify bool   `json:"skip_tls_verify"`
-	CA            string `json:"ca"`
-	ClientCert    string `json:"client_cert"`
-	ClientKey     string `json:"client_key"`
+//inferType sets the check type if it is not set, to http_status if the instances are urls,
+//or tcp_connect, and creates the checker for it
+func (co *CheckOptions) inferType(instances []*Instance) (err error) {
+	if co.checker != nil {
+		return nil
+	}
+	if co.Stype == "" {
+		co.Stype = TCPConnect
+		if len(instances) > 0 && strings.HasPrefix(instances[0].address, "http") {
+			co.Stype = HTTPStatus
+		}
+	}
+	co.checker, err = newChecker(co.Stype, co.raw)
+	return err
+}
+
+//MarshalJSON marshals the data structure to a byte array, including the options of the checker
+func (co *CheckOptions) MarshalJSON() ([]byte, error) {
+	type checkOptions CheckOptions
+	b, err := json.Marshal((*checkOptions)(co))
+	if err != nil || co.checker == nil {
+		return b, err
+	}
+	cb, err := json.Marshal(co.checker)
+	if err != nil {
+		return nil, err
+	}
+	var o, c map[string]json.RawMessage
+	if err = json.Unmarshal(b, &o); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(cb, &c); err != nil {
+		// checker does not marshal to an object, it has no options to show
+		return b, nil
+	}
+	for k, v := range c {
+		if _, ok := o[k]; !ok {
+			o[k] = v
+		}
+	}
+	return json.Marshal(o)
+}
+
+//interval returns the time between checks, which defaults to 10s
+func (co *CheckOptions) interval() time.Duration {
+	if co.Interval <= 0 {
+		return defaultInterval
+	}
+	return time.Duration(co.Interval)
+}
+
+//timeout returns the limit of a single attempt, which defaults to, and is at most, the interval
+func (co *CheckOptions) timeout() time.Duration {
+	if co.Timeout <= 0 || time.Duration(co.Timeout) > co.interval() {
+		return co.interval()
+	}
+	return time.Duration(co.Timeout)
+}
+
+//rise returns the consecutive up results needed to change the instance to up, which defaults to 1
+func (co *CheckOptions) rise() int {
+	if co.Rise < 1 {
+		return 1
+	}
+	return co.Rise
+}
+
+//fall returns the consecutive down results needed to change the instance to down, which defaults to 1
+func (co *CheckOptions) fall() int {
+	if co.Fall < 1 {
+		return 1
+	}
+	return co.Fall
+}
+
+//checkResponseTime marks the result down if the response time is over the max_response_time,
+//or slow and degraded if it is over the warn_response_time
+func (co *CheckOptions) checkResponseTime(r *CheckResult, rt time.Duration) {
+	if !r.Up {
+		return
+	}
+	switch {
+	case co.MaxResponseTime > 0 && rt > time.Duration(co.MaxResponseTime):
+		r.Up = false
+		r.Err = fmt.Errorf("response time %v over max_response_time %v", rt, time.Duration(co.MaxResponseTime))
+	case co.WarnResponseTime > 0 && rt > time.Duration(co.WarnResponseTime):
+		r.Slow = true
+		r.degrade(fmt.Sprintf("response time %v over warn_response_time %v", rt, time.Duration(co.WarnResponseTime)))
+	}
+}
+
+//check checks the instance, or each of the addresses it resolves to with resolve_all, within the interval.
+//It returns the result, the number of attempts, and the time the last attempt took.
+func (co *CheckOptions) check(address string) (CheckResult, int, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), co.interval())
+	defer cancel()
+	if co.ResolveAll {
+		return co.checkAll(ctx, address)
+	}
+	return co.checkRetry(ctx, address)
+}
+
+//checkRetry checks the address, retrying failures until the retries are used up or the context is done
+func (co *CheckOptions) checkRetry(ctx context.Context, address string) (r CheckResult, attempts int, rt time.Duration) {
+	for {
+		attempts++
+		start := time.Now()
+		trace := &connTrace{}
+		actx, acancel := context.WithTimeout(trace.withContext(ctx), co.timeout())
+		r = co.checker.Check(actx, address)
+		acancel()
+		rt = time.Since(start)
+		if r.ResolvedIP == "" {
+			r.ResolvedIP = trace.ip()
+		}
+		co.checkResponseTime(&r, rt)
+		if r.Up || attempts > co.Retries {
+			return r, attempts, rt
+		}
+		select {
+		case <-time.After(time.Duration(co.RetryDelay)):
+		case <-ctx.Done():
+			return r, attempts, rt
+		}
+		if ctx.Err() != nil {
+			return r, attempts, rt
+		}
+	}
 }
 
 //Service represents a collection of like instances on multiple hosts
 //to provide a single service in a redundant fashion
 type Service struct {
-	Instances    []*Instance   `json:"instances"`
-	MaxFailures  int           `json:"max_failures"`
-	CheckOptions *CheckOptions `json:"check_options"`
+	Instances    []*Instance    `json:"instances"`
+	MaxFailures  int            `json:"max_failures"`
+	RequireRoles map[string]int `json:"require_roles,omitempty"`
+	CheckOptions *CheckOptions  `json:"check_options"`
 	broker       *serviceBroker
 	brokerLock   sync.Mutex
 }
@@ -50,9 +220,12 @@ type ServiceStatus struct {
 	Degraded        bool                      `json:"degraded"`
 	Failed          bool                      `json:"failed"`
 	MaxFailures     int                       `json:"max_failures"`
+	RequireRoles    map[string]int            `json:"require_roles,omitempty"`
+	Roles           map[string]int            `json:"roles,omitempty"`
 	InstancesTotal  int                       `json:"instances_total"`
 	InstancesUp     int                       `json:"instances_up"`
 	InstancesFailed int                       `json:"instances_failed"`
+	InstancesSlow   int                       `json:"instances_slow"`
 	TimeStamp       time.Time                 `json:"timestamp"`
 	LastChange      time.Time                 `json:"last_change"`
 	idx, cidx       uint64
@@ -67,6 +240,19 @@ func (ss *ServiceStatus) filter(depth uint8) {
 	}
 }
 
+//UnmarshalJSON unmarshals the JSON bytes, and creates the checker, so invalid check options fail the config
+func (s *Service) UnmarshalJSON(data []byte) (err error) {
+	type service Service
+	err = json.Unmarshal(data, (*service)(s))
+	if err != nil {
+		return err
+	}
+	if s.CheckOptions == nil {
+		s.CheckOptions = &CheckOptions{}
+	}
+	return s.CheckOptions.inferType(s.Instances)
+}
+
 //StartChecks starts checking the corresponding instances
 func (s *Service) StartChecks() {
 	if s.broker == nil {
@@ -84,8 +270,16 @@ func (s *Service) startSubscriptions() {
 		s.CheckOptions = &CheckOptions{}
 	}
 
-	if s.CheckOptions.Interval == 0 {
-		s.CheckOptions.Interval = Interval(10 * time.Second)
+	// the checks apply the defaults themselves, they are set here to be shown in the config api
+	s.CheckOptions.Interval = Interval(s.CheckOptions.interval())
+	s.CheckOptions.Timeout = Interval(s.CheckOptions.timeout())
+	s.CheckOptions.Rise = s.CheckOptions.rise()
+	s.CheckOptions.Fall = s.CheckOptions.fall()
+
+	// services from the config already have their checker, this is for those created otherwise
+	err := s.CheckOptions.inferType(s.Instances)
+	if err != nil {
+		log.WithError(err).WithField("type", s.CheckOptions.Stype).Error("Failed to create checker")
 	}
 
 	type instanceStatusUpdate struct {
@@ -94,35 +288,15 @@ func (s *Service) startSubscriptions() {
 	}
 	updates := make(chan *instanceStatusUpdate)
 	for _, i := range s.Instances {
-		if s.CheckOptions.Stype == "" {
-			s.CheckOptions.Stype = TCPConnect
-			if strings.HasPrefix("http", i.address) {
-				s.CheckOptions.Stype = HTTPStatus
-				if s.CheckOptions.HTTPMethod == "" {
-					s.CheckOptions.HTTPMethod = "GET"
-				}
-			}
-		}
-		if s.CheckOptions.Stype == HTTPStatus {
-			if s.CheckOptions.HTTPOpts == nil {
-				s.CheckOptions.HTTPOpts = &HTTPOpts{}
-			}
-			if s.CheckOptions.HTTPOpts.HTTPMethod == "" {
-				s.CheckOptions.HTTPOpts.HTTPMethod = s.CheckOptions.HTTPMethod
-			}
-			if s.CheckOptions.HTTPOpts.HTTPMethod == "" {
-				s.CheckOptions.HTTPOpts.HTTPMethod = "GET"
-			}
-		}
 		i.StartChecks(s.CheckOptions)
 		go func(i *Instance) {
 			iSub := i.Subscribe(true, 255, 0, false)
 			defer iSub.Close()
 			var lc time.Time
-			var ls bool
+			var ls, ld bool
 			for is := range iSub.C {
-				if is.Up != ls {
-					ls = is.Up
+				if is.Up != ls || is.Degraded != ld {
+					ls, ld = is.Up, is.Degraded
 					lc = is.TimeStamp
 				}
 				is.LastChange = lc
@@ -143,10 +317,11 @@ func (s *Service) startSubscriptions() {
 			l := log.WithField("name", isu.name).WithField("status", isu.s)
 			l.Debug("Received status update")
 			iss := ServiceStatus{
-				Instances:   map[string]InstanceStatus{isu.name: isu.s},
-				MaxFailures: s.MaxFailures,
-				idx:         idx,
-				cidx:        cidx,
+				Instances:    map[string]InstanceStatus{isu.name: isu.s},
+				MaxFailures:  s.MaxFailures,
+				RequireRoles: s.RequireRoles,
+				idx:          idx,
+				cidx:         cidx,
 			}
 			go func(iss ServiceStatus) { s.broker.notifier <- iss }(iss)
 		}
@@ -157,13 +332,27 @@ func (ss *ServiceStatus) recalculate() {
 	ss.InstancesTotal = 0
 	ss.InstancesFailed = 0
 	ss.InstancesUp = 0
+	ss.InstancesSlow = 0
 	ss.AvgResponseTime = time.Duration(0)
 	ss.Degraded = false
 	ss.Failed = false
+	ss.Roles = nil
 	for _, is := range ss.Instances {
 		ss.InstancesTotal++
+		if is.Up && is.Role != "" {
+			if ss.Roles == nil {
+				ss.Roles = make(map[string]int)
+			}
+			ss.Roles[is.Role]++
+		}
 		if is.Up {
 			ss.InstancesUp++
+			if is.Degraded {
+				ss.Degraded = true
+			}
+			if is.Slow {
+				ss.InstancesSlow++
+			}
 		} else {
 			ss.InstancesFailed++
 			ss.Degraded = true
@@ -174,6 +363,11 @@ func (ss *ServiceStatus) recalculate() {
 		ss.AvgResponseTime = ss.AvgResponseTime / time.Duration(ss.InstancesTotal)
 	}
 	ss.Failed = ss.InstancesFailed > ss.MaxFailures
+	for r, n := range ss.RequireRoles {
+		if ss.Roles[r] != n {
+			ss.Failed = true
+		}
+	}
 }
 
 func (ss *ServiceStatus) updateFrom(iss *ServiceStatus) {
@@ -184,6 +378,7 @@ func (ss *ServiceStatus) updateFrom(iss *ServiceStatus) {
 		ss.cidx = iss.cidx
 	}
 	ss.MaxFailures = iss.MaxFailures
+	ss.RequireRoles = iss.RequireRoles
 	if ss.Instances == nil {
 		ss.Instances = make(map[string]InstanceStatus)
 	}
@@ -204,7 +399,21 @@ func (ss *ServiceStatus) summaryEquals(iss *ServiceStatus) bool {
 		ss.InstancesTotal == iss.InstancesTotal &&
 		ss.InstancesFailed == iss.InstancesFailed &&
 		ss.InstancesUp == iss.InstancesUp &&
-		ss.AvgResponseTime == iss.AvgResponseTime
+		ss.InstancesSlow == iss.InstancesSlow &&
+		ss.AvgResponseTime == iss.AvgResponseTime &&
+		rolesEqual(ss.Roles, iss.Roles)
+}
+
+func rolesEqual(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for r, n := range a {
+		if b[r] != n {
+			return false
+		}
+	}
+	return true
 }
 
 func (ss *ServiceStatus) copySummaryFrom(iss *ServiceStatus) bool {
@@ -226,7 +435,10 @@ func (ss *ServiceStatus) copySummaryFrom(iss *ServiceStatus) bool {
 	ss.InstancesTotal = iss.InstancesTotal
 	ss.InstancesFailed = iss.InstancesFailed
 	ss.InstancesUp = iss.InstancesUp
+	ss.InstancesSlow = iss.InstancesSlow
 	ss.AvgResponseTime = iss.AvgResponseTime
+	ss.Roles = iss.Roles
+	ss.RequireRoles = iss.RequireRoles
 	ss.Degraded = iss.Degraded
 	ss.Failed = iss.Failed
 	return true
@@ -250,6 +462,15 @@ func (ss *ServiceStatus) contains(iss *ServiceStatus, depth uint8) bool {
 		if i.Up != ssi.Up {
 			return false
 		}
+		if i.Degraded != ssi.Degraded {
+			return false
+		}
+		if i.Role != ssi.Role {
+			return false
+		}
+		if i.Slow != ssi.Slow {
+			return false
+		}
 	}
 	return true
 }
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func TestCheckOptionsDefaults(t *testing.T) {
+	tests := []struct {
+		name              string
+		co                CheckOptions
+		interval, timeout time.Duration
+		rise, fall        int
+	}{
+		{"zero", CheckOptions{}, defaultInterval, defaultInterval, 1, 1},
+		{"interval", CheckOptions{Interval: Interval(time.Second)}, time.Second, time.Second, 1, 1},
+		{"timeout", CheckOptions{Interval: Interval(time.Second), Timeout: Interval(100 * time.Millisecond)}, time.Second, 100 * time.Millisecond, 1, 1},
+		{"timeout over interval", CheckOptions{Interval: Interval(time.Second), Timeout: Interval(time.Minute)}, time.Second, time.Second, 1, 1},
+		{"rise and fall", CheckOptions{Rise: 2, Fall: 3}, defaultInterval, defaultInterval, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := tt.co.interval(); v != tt.interval {
+				t.Errorf("interval is %v, expected %v", v, tt.interval)
+			}
+			if v := tt.co.timeout(); v != tt.timeout {
+				t.Errorf("timeout is %v, expected %v", v, tt.timeout)
+			}
+			if tt.co.rise() != tt.rise || tt.co.fall() != tt.fall {
+				t.Errorf("rise and fall are %v and %v, expected %v and %v", tt.co.rise(), tt.co.fall(), tt.rise, tt.fall)
+			}
+		})
+	}
+}
+
+//TestInstanceStartChecks checks an instance started without a service, which applies no defaults
+func TestInstanceStartChecks(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	var co CheckOptions
+	err = json.Unmarshal([]byte(`{"type": "tcp_connect"}`), &co)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var i Instance
+	err = json.Unmarshal([]byte(`"`+l.Addr().String()+`"`), &i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.StartChecks(&co)
+	sub := i.Subscribe(true, 0, 0, false)
+	defer sub.Close()
+	select {
+	case is := <-sub.C:
+		if !is.Up {
+			t.Errorf("instance is down: %v", is.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status from instance")
+	}
+}
+
+func TestCheckResponseTime(t *testing.T) {
+	co := &CheckOptions{
+		WarnResponseTime: Interval(100 * time.Millisecond),
+		MaxResponseTime:  Interval(time.Second),
+	}
+	tests := []struct {
+		name       string
+		r          CheckResult
+		rt         time.Duration
+		up, slow   bool
+		errorClass string
+		status     string
+	}{
+		{"fast", CheckResult{Up: true, Status: "ok"}, 50 * time.Millisecond, true, false, "", "ok"},
+		{"slow", CheckResult{Up: true}, 200 * time.Millisecond, true, true, "", "response time 200ms over warn_response_time 100ms"},
+		{"slow with status", CheckResult{Up: true, Status: "leader"}, 200 * time.Millisecond, true, true, "", "leader, response time 200ms over warn_response_time 100ms"},
+		{"too slow", CheckResult{Up: true}, 2 * time.Second, false, false, ErrorClassAssertion, ""},
+		{"down", CheckResult{Err: &classError{ErrorClassRefused, errTest}}, 2 * time.Second, false, false, ErrorClassRefused, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.r
+			co.checkResponseTime(&r, tt.rt)
+			if r.Up != tt.up || r.Slow != tt.slow || r.Degraded != tt.slow {
+				t.Errorf("up %v slow %v degraded %v, expected up %v slow %v", r.Up, r.Slow, r.Degraded, tt.up, tt.slow)
+			}
+			if c := classifyError(r.Err); c != tt.errorClass {
+				t.Errorf("error class is %q, expected %q: %v", c, tt.errorClass, r.Err)
+			}
+			if r.Status != tt.status {
+				t.Errorf("status is %q, expected %q", r.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestServiceInferType(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		stype   string
+	}{
+		{"http", `{"instances": ["http://localhost/"], "check_options": {"http_options": {"expect_body_contains": "ok"}}}`, HTTPStatus},
+		{"tcp", `{"instances": ["localhost:22"], "check_options": {"interval": "5s"}}`, TCPConnect},
+		{"no check options", `{"instances": ["localhost:22"]}`, TCPConnect},
+		{"set", `{"instances": ["http://localhost/"], "check_options": {"type": "tcp_connect"}}`, TCPConnect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Service
+			err := json.Unmarshal([]byte(tt.service), &s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.CheckOptions.Stype != tt.stype || s.CheckOptions.checker == nil {
+				t.Errorf("type is %v with checker %v, expected %v", s.CheckOptions.Stype, s.CheckOptions.checker, tt.stype)
+			}
+		})
+	}
+
+	var s Service
+	err := json.Unmarshal([]byte(`{"instances": ["http://localhost/"], "check_options": {"http_options": {"expect_body_regex": "("}}}`), &s)
+	if err == nil {
+		t.Error("expected error for invalid options of the inferred type")
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//StatusCodes is a list of http status codes and ranges of codes, such as [200, 204, "300-308"]
+type StatusCodes []StatusCodeRange
+
+//StatusCodeRange is an inclusive range of http status codes
+type StatusCodeRange struct {
+	Min, Max int
+}
+
+var defaultStatusCodes = StatusCodes{{Min: 200, Max: 399}}
+
+//Contains returns true if the code is in any of the ranges
+func (sc StatusCodes) Contains(code int) bool {
+	for _, r := range sc {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+//UnmarshalJSON unmarshals bytes into a StatusCodeRange
+func (r *StatusCodeRange) UnmarshalJSON(data []byte) (err error) {
+	var c int
+	if err = json.Unmarshal(data, &c); err == nil {
+		r.Min, r.Max = c, c
+		return nil
+	}
+
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	p := strings.SplitN(s, "-", 2)
+	r.Min, err = strconv.Atoi(strings.TrimSpace(p[0]))
+	if err != nil {
+		return fmt.Errorf("invalid status code %q", s)
+	}
+	r.Max = r.Min
+	if len(p) > 1 {
+		r.Max, err = strconv.Atoi(strings.TrimSpace(p[1]))
+		if err != nil {
+			return fmt.Errorf("invalid status code range %q", s)
+		}
+	}
+	if r.Min > r.Max {
+		return fmt.Errorf("invalid status code range %q", s)
+	}
+	return nil
+}
+
+//MarshalJSON converts the StatusCodeRange to a JSON number, or a string range
+func (r StatusCodeRange) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max {
+		return json.Marshal(r.Min)
+	}
+	return json.Marshal(fmt.Sprintf("%d-%d", r.Min, r.Max))
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Threshold is a comparison such as "> 0" or "<= 2" which is breached when it is true.
+//A bare number is breached when the value is greater than it.
+type Threshold struct {
+	Op    string
+	Value float64
+}
+
+var thresholdOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+//Breached returns true if the value breaches the threshold
+func (t *Threshold) Breached(v float64) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Op {
+	case ">=":
+		return v >= t.Value
+	case "<=":
+		return v <= t.Value
+	case "==":
+		return v == t.Value
+	case "!=":
+		return v != t.Value
+	case "<":
+		return v < t.Value
+	default:
+		return v > t.Value
+	}
+}
+
+func (t *Threshold) String() string {
+	return t.Op + " " + strconv.FormatFloat(t.Value, 'g', -1, 64)
+}
+
+//UnmarshalJSON unmarshals a number or a comparison string into a Threshold
+func (t *Threshold) UnmarshalJSON(data []byte) (err error) {
+	if err = json.Unmarshal(data, &t.Value); err == nil {
+		t.Op = ">"
+		return nil
+	}
+
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.TrimSpace(s)
+	t.Op = ">"
+	for _, op := range thresholdOps {
+		if strings.HasPrefix(s, op) {
+			t.Op = op
+			s = s[len(op):]
+			break
+		}
+	}
+	t.Value, err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return fmt.Errorf("invalid threshold %q", string(data))
+	}
+	return nil
+}
+
+//MarshalJSON converts the Threshold to a comparison string
+func (t *Threshold) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+//thresholds are the warn and critical thresholds of a value
+type thresholds struct {
+	Warn     *Threshold `json:"warn,omitempty"`
+	Critical *Threshold `json:"critical,omitempty"`
+}
+
+//apply records the named value in the result, and marks the result down if the
+//critical threshold is breached, or degraded if the warn threshold is breached
+func (t *thresholds) apply(r *CheckResult, name string, v float64) {
+	if r.Values == nil {
+		r.Values = make(map[string]float64)
+	}
+	r.Values[name] = v
+	switch {
+	case t.Critical.Breached(v):
+		r.Up = false
+		r.Err = fmt.Errorf("%v is %v, critical %v", name, v, t.Critical)
+	case t.Warn.Breached(v) && r.Up:
+		r.degrade(fmt.Sprintf("%v is %v, warn %v", name, v, t.Warn))
+	}
+}